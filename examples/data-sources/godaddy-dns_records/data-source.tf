@@ -0,0 +1,16 @@
+# MX records managed by somebody else
+data "godaddy-dns_records" "mx" {
+  domain = "mydomain.com"
+  type   = "MX"
+  name   = "@"
+}
+
+# all the records for domain top (A, MX, TXT etc)
+data "godaddy-dns_records" "top" {
+  domain = "mydomain.com"
+  name   = "@"
+}
+
+output "mail_servers" {
+  value = [for r in data.godaddy-dns_records.mx.records : r.data]
+}
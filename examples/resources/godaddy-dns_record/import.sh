@@ -1,2 +1,10 @@
 # id is <domain>:<type>:<name>:<data>
 terraform import godaddy-dns_record.cname-alias mydom.com:CNAME:alias:test.com
+# for SRV: <domain>:SRV:<name>:<service>:<protocol>:<port>:<data>
+terraform import godaddy-dns_record.sip mydom.com:SRV:@:_sip:_tcp:5060:sip.mydom.com
+# data could be omitted if there is only one record of type + name (like CNAME)
+terraform import godaddy-dns_record.cname-alias mydom.com:CNAME:alias
+# for MX: optional priority before data
+terraform import godaddy-dns_record.mx mydom.com:MX:@:10:mx1.mydom.com
+# colons in data could be left as is, or escaped with \: (and \\ for backslash)
+terraform import godaddy-dns_record.ipv6 'mydom.com:AAAA:www:2001:db8::1'
@@ -0,0 +1,2 @@
+# id is <domain>:<type>:<name>; imported set takes all the values and is exclusive
+terraform import godaddy-dns_record_set.mx mydomain.com:MX:@
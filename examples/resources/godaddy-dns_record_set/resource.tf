@@ -0,0 +1,22 @@
+# all MX records for the domain in one PUT; other MXes are removed
+resource "godaddy-dns_record_set" "mx" {
+  domain    = "mydomain.com"
+  type      = "MX"
+  name      = "@"
+  exclusive = true
+  records = [
+    { data = "mx01.mail.icloud.com", priority = 10 },
+    { data = "mx02.mail.icloud.com", priority = 10 },
+  ]
+}
+
+# some TXT records for the domain top, keeping the rest intact
+resource "godaddy-dns_record_set" "txt" {
+  domain = "mydomain.com"
+  type   = "TXT"
+  name   = "@"
+  records = [
+    { data = "v=spf1 include:icloud.com ~all" },
+    { data = "google-site-verification=xxx", ttl = 600 },
+  ]
+}
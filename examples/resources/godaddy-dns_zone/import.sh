@@ -0,0 +1,2 @@
+# id is just the domain name
+terraform import godaddy-dns_zone.main mydomain.com
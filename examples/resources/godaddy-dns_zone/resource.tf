@@ -0,0 +1,15 @@
+# all the records for the domain: anything not listed here is removed,
+# except for SOA, top-level NS, `_domainconnect` and ignored records
+resource "godaddy-dns_zone" "main" {
+  domain = "mydomain.com"
+  records = [
+    { type = "A", name = "@", data = "1.2.3.4" },
+    { type = "CNAME", name = "www", data = "@" },
+    { type = "MX", name = "@", data = "mx01.mail.icloud.com", priority = 10 },
+    { type = "TXT", name = "@", data = "v=spf1 include:icloud.com ~all", ttl = 600 },
+  ]
+  # managed elsewhere
+  ignore = [
+    { name = "_acme-challenge*" },
+  ]
+}
@@ -0,0 +1,212 @@
+package client
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/veksh/terraform-provider-godaddy-dns/internal/model"
+)
+
+var _ model.DNSApiClient = &CachingClient{}
+
+// caching decorator for API client: results of GetRecords are kept in memory
+// for the lifetime of provider (one terraform run)
+//   - answers for type+name could be served from cached type or whole domain
+//   - concurrent queries for the same key result in one API call
+//   - successful writes patch cached entries, failed ones drop them
+//     (result is unknown)
+//   - errors are not cached
+type CachingClient struct {
+	next    model.DNSApiClient
+	mu      sync.Mutex
+	entries map[cacheKey]*cacheEntry
+}
+
+// type and name are empty for whole domain, name is empty for all records of type
+type cacheKey struct {
+	shopperID string
+	domain    model.DNSDomain
+	rType     model.DNSRecordType
+	rName     model.DNSRecordName
+}
+
+type cacheEntry struct {
+	// closed when query is finished
+	ready chan struct{}
+	recs  []model.DNSRecord
+	err   error
+}
+
+func NewCachingClient(next model.DNSApiClient) *CachingClient {
+	return &CachingClient{
+		next:    next,
+		entries: map[cacheKey]*cacheEntry{},
+	}
+}
+
+// key and its wider versions: type, then whole domain
+func (k cacheKey) wider() []cacheKey {
+	res := []cacheKey{}
+	if k.rName != "" {
+		res = append(res, cacheKey{k.shopperID, k.domain, k.rType, ""})
+	}
+	if k.rType != "" {
+		res = append(res, cacheKey{k.shopperID, k.domain, "", ""})
+	}
+	return res
+}
+
+func (k cacheKey) matches(rec model.DNSRecord) bool {
+	return (k.rType == "" || rec.Type == k.rType) && (k.rName == "" || rec.Name == k.rName)
+}
+
+func (c *CachingClient) GetRecords(ctx context.Context, rDomain model.DNSDomain,
+	rType model.DNSRecordType, rName model.DNSRecordName) ([]model.DNSRecord, error) {
+
+	key := cacheKey{ShopperIDFromContext(ctx), rDomain, rType, rName}
+
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	if !ok {
+		// could be answered from wider query, if it is already done
+		for _, wk := range key.wider() {
+			if we, ok := c.entries[wk]; ok && we.done() {
+				c.mu.Unlock()
+				return filterRecs(we.recs, key), nil
+			}
+		}
+		entry = &cacheEntry{ready: make(chan struct{})}
+		c.entries[key] = entry
+		c.mu.Unlock()
+
+		entry.recs, entry.err = c.next.GetRecords(ctx, rDomain, rType, rName)
+		if entry.err != nil {
+			c.mu.Lock()
+			if c.entries[key] == entry {
+				delete(c.entries, key)
+			}
+			c.mu.Unlock()
+		}
+		close(entry.ready)
+	} else {
+		c.mu.Unlock()
+		select {
+		case <-entry.ready:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	if entry.err != nil {
+		return nil, entry.err
+	}
+	return slices.Clone(entry.recs), nil
+}
+
+func (e *cacheEntry) done() bool {
+	select {
+	case <-e.ready:
+		return e.err == nil
+	default:
+		return false
+	}
+}
+
+func filterRecs(recs []model.DNSRecord, key cacheKey) []model.DNSRecord {
+	res := []model.DNSRecord{}
+	for _, rec := range recs {
+		if key.matches(rec) {
+			res = append(res, rec)
+		}
+	}
+	return res
+}
+
+// update all the cached entries for domain: replace records of type + name
+// with the given ones, append to them (if add is true), or drop entries
+// (if recs is nil)
+func (c *CachingClient) patch(ctx context.Context, rDomain model.DNSDomain,
+	rType model.DNSRecordType, rName model.DNSRecordName, recs []model.DNSRecord, add bool) {
+
+	shopperID := ShopperIDFromContext(ctx)
+	changed := cacheKey{shopperID, rDomain, rType, rName}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, entry := range c.entries {
+		if key.shopperID != shopperID || key.domain != rDomain {
+			continue
+		}
+		if key.rType != "" && key.rType != rType || key.rName != "" && key.rName != rName {
+			continue
+		}
+		if recs == nil || !entry.done() {
+			delete(c.entries, key)
+			continue
+		}
+		newRecs := []model.DNSRecord{}
+		for _, rec := range entry.recs {
+			// exact entry is replaced completely
+			if add || (key != changed && !changed.matches(rec)) {
+				newRecs = append(newRecs, rec)
+			}
+		}
+		// replace entry: readers could still hold the old one
+		c.entries[key] = &cacheEntry{
+			ready: entry.ready,
+			recs:  append(newRecs, recs...),
+		}
+	}
+}
+
+func (c *CachingClient) AddRecords(ctx context.Context, rDomain model.DNSDomain, records []model.DNSRecord) error {
+	err := c.next.AddRecords(ctx, rDomain, records)
+	// records could be of different types and names
+	for _, rec := range records {
+		if err != nil {
+			c.patch(ctx, rDomain, rec.Type, rec.Name, nil, false)
+		} else {
+			c.patch(ctx, rDomain, rec.Type, rec.Name, []model.DNSRecord{rec}, true)
+		}
+	}
+	return err
+}
+
+func (c *CachingClient) SetRecords(ctx context.Context, rDomain model.DNSDomain,
+	rType model.DNSRecordType, rName model.DNSRecordName, records []model.DNSUpdateRecord) error {
+
+	err := c.next.SetRecords(ctx, rDomain, rType, rName, records)
+	if err != nil {
+		c.patch(ctx, rDomain, rType, rName, nil, false)
+		return err
+	}
+	recs := make([]model.DNSRecord, 0, len(records))
+	for _, ur := range records {
+		recs = append(recs, model.DNSRecord{
+			Type:     rType,
+			Name:     rName,
+			Data:     ur.Data,
+			TTL:      ur.TTL,
+			Priority: ur.Priority,
+			Service:  ur.Service,
+			Protocol: ur.Protocol,
+			Port:     ur.Port,
+			Weight:   ur.Weight,
+		})
+	}
+	c.patch(ctx, rDomain, rType, rName, recs, false)
+	return nil
+}
+
+func (c *CachingClient) DelRecords(ctx context.Context, rDomain model.DNSDomain,
+	rType model.DNSRecordType, rName model.DNSRecordName) error {
+
+	err := c.next.DelRecords(ctx, rDomain, rType, rName)
+	if err != nil {
+		c.patch(ctx, rDomain, rType, rName, nil, false)
+		return err
+	}
+	c.patch(ctx, rDomain, rType, rName, []model.DNSRecord{}, false)
+	return nil
+}
@@ -0,0 +1,155 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/mock"
+	"github.com/veksh/terraform-provider-godaddy-dns/internal/model"
+)
+
+var (
+	cacheTestRecs = []model.DNSRecord{
+		{Type: model.REC_A, Name: "www", Data: "1.1.1.1", TTL: 3600},
+		{Type: model.REC_TXT, Name: "@", Data: "txt 1", TTL: 3600},
+		{Type: model.REC_TXT, Name: "@", Data: "txt 2", TTL: 3600},
+		{Type: model.REC_TXT, Name: "other", Data: "txt 3", TTL: 3600},
+	}
+	cacheTestTXT = cacheTestRecs[1:3]
+)
+
+func TestCachingClient_QueriesOnce(t *testing.T) {
+	t.Parallel()
+	mClient := model.NewMockDNSApiClient(t)
+	mClient.EXPECT().GetRecords(mock.Anything, model.DNSDomain("test.com"), model.REC_TXT, model.DNSRecordName("@")).
+		After(10*time.Millisecond).Return(cacheTestTXT, nil).Once()
+	c := NewCachingClient(mClient)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			got, err := c.GetRecords(context.Background(), "test.com", model.REC_TXT, "@")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if !cmp.Equal(cacheTestTXT, got) {
+				t.Error(cmp.Diff(cacheTestTXT, got))
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestCachingClient_AnswersFromDomain(t *testing.T) {
+	t.Parallel()
+	mClient := model.NewMockDNSApiClient(t)
+	mClient.EXPECT().GetRecords(mock.Anything, model.DNSDomain("test.com"), model.DNSRecordType(""), model.DNSRecordName("")).
+		Return(cacheTestRecs, nil).Once()
+	c := NewCachingClient(mClient)
+
+	if _, err := c.GetRecords(context.Background(), "test.com", "", ""); err != nil {
+		t.Fatal(err)
+	}
+	got, err := c.GetRecords(context.Background(), "test.com", model.REC_TXT, "@")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(cacheTestTXT, got) {
+		t.Error(cmp.Diff(cacheTestTXT, got))
+	}
+	// shopper is a part of the key
+	mClient.EXPECT().GetRecords(mock.Anything, model.DNSDomain("test.com"), model.REC_TXT, model.DNSRecordName("@")).
+		Return(nil, nil).Once()
+	_, err = c.GetRecords(ContextWithShopperID(context.Background(), "12345"), "test.com", model.REC_TXT, "@")
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCachingClient_PatchesOnWrite(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	mClient := model.NewMockDNSApiClient(t)
+	mClient.EXPECT().GetRecords(mock.Anything, model.DNSDomain("test.com"), model.DNSRecordType(""), model.DNSRecordName("")).
+		Return(cacheTestRecs, nil).Once()
+	mClient.EXPECT().GetRecords(mock.Anything, model.DNSDomain("test.com"), model.REC_TXT, model.DNSRecordName("@")).
+		Return(cacheTestTXT, nil).Once()
+	mClient.EXPECT().AddRecords(mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mClient.EXPECT().SetRecords(mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mClient.EXPECT().DelRecords(mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	c := NewCachingClient(mClient)
+	// exact query first, or it will be answered from domain
+	for _, q := range []struct{ rType, rName string }{{"TXT", "@"}, {"", ""}} {
+		if _, err := c.GetRecords(ctx, "test.com", model.DNSRecordType(q.rType), model.DNSRecordName(q.rName)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	check := func(step string, rType model.DNSRecordType, rName model.DNSRecordName, want []model.DNSRecord) {
+		t.Helper()
+		got, err := c.GetRecords(ctx, "test.com", rType, rName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !cmp.Equal(want, got) {
+			t.Errorf("%s: %s", step, cmp.Diff(want, got))
+		}
+	}
+
+	newTXT := model.DNSRecord{Type: model.REC_TXT, Name: "@", Data: "txt 4", TTL: 600}
+	if err := c.AddRecords(ctx, "test.com", []model.DNSRecord{newTXT}); err != nil {
+		t.Fatal(err)
+	}
+	check("add", model.REC_TXT, "@", append(cacheTestTXT[:2:2], newTXT))
+	check("add (domain)", "", "", append(cacheTestRecs[:4:4], newTXT))
+
+	err := c.SetRecords(ctx, "test.com", model.REC_TXT, "@", []model.DNSUpdateRecord{newTXT.ToUpdate()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	check("set", model.REC_TXT, "@", []model.DNSRecord{newTXT})
+	check("set (domain)", "", "", []model.DNSRecord{cacheTestRecs[0], cacheTestRecs[3], newTXT})
+
+	if err := c.DelRecords(ctx, "test.com", model.REC_TXT, "@"); err != nil {
+		t.Fatal(err)
+	}
+	check("del", model.REC_TXT, "@", []model.DNSRecord{})
+	check("del (domain)", "", "", []model.DNSRecord{cacheTestRecs[0], cacheTestRecs[3]})
+	// not affected by writes to other names
+	check("del (other)", model.REC_TXT, "other", cacheTestRecs[3:4])
+}
+
+func TestCachingClient_DropsOnFailure(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	mClient := model.NewMockDNSApiClient(t)
+	mClient.EXPECT().GetRecords(mock.Anything, model.DNSDomain("test.com"), model.REC_TXT, model.DNSRecordName("@")).
+		Return(nil, errors.New("network error")).Once()
+	mClient.EXPECT().GetRecords(mock.Anything, model.DNSDomain("test.com"), model.REC_TXT, model.DNSRecordName("@")).
+		Return(cacheTestTXT, nil).Twice()
+	mClient.EXPECT().SetRecords(mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(errors.New("api error"))
+	c := NewCachingClient(mClient)
+
+	// errors are not cached
+	if _, err := c.GetRecords(ctx, "test.com", model.REC_TXT, "@"); err == nil {
+		t.Fatal("want error")
+	}
+	if _, err := c.GetRecords(ctx, "test.com", model.REC_TXT, "@"); err != nil {
+		t.Fatal(err)
+	}
+	// failed write drops the entry
+	if err := c.SetRecords(ctx, "test.com", model.REC_TXT, "@", nil); err == nil {
+		t.Fatal("want error")
+	}
+	if _, err := c.GetRecords(ctx, "test.com", model.REC_TXT, "@"); err != nil {
+		t.Fatal(err)
+	}
+}
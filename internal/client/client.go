@@ -9,11 +9,11 @@ import (
 	"net"
 	"net/http"
 	"net/url"
+	"strconv"
 	"time"
 
 	"github.com/pkg/errors"
 	"github.com/veksh/terraform-provider-godaddy-dns/internal/model"
-	"github.com/veksh/terraform-provider-godaddy-dns/libs/ratelimiter"
 )
 
 // also: https://github.com/go-resty/resty
@@ -23,13 +23,17 @@ import (
 
 const (
 	HTTP_TIMEOUT = 10
-	// burst RL: not currently used
-	HTTP_RPS   = 1
-	HTTP_BURST = 60
-	// window RL: window size, max requests per window
+	// default rate limit: window size, max requests per window
 	HTTP_RATE_WINDOW = time.Duration(60) * time.Second
 	HTTP_RATE_RPW    = 60
 	DOMAINS_URL      = "/v1/domains/"
+	// retries on 429 and 5xx: max attempts, first and max backoff interval
+	RETRY_MAX      = 3
+	RETRY_MIN_WAIT = time.Duration(1) * time.Second
+	RETRY_MAX_WAIT = time.Duration(30) * time.Second
+	// records per page for GET, max pages per request
+	GET_PAGE_SIZE = 500
+	GET_MAX_PAGES = 100
 )
 
 var _ model.DNSApiClient = Client{}
@@ -39,10 +43,68 @@ type Client struct {
 	apiURL     string
 	key        string
 	secret     string
+	shopperID  string
 	httpClient http.Client
 }
 
-func NewClient(apiURL string, key string, secret string) (*Client, error) {
+// optional client settings
+type clientOptions struct {
+	shopperID    string
+	maxRetries   int
+	retryMinWait time.Duration
+	retryMaxWait time.Duration
+	rateLimit    RateLimit
+}
+
+type Option func(*clientOptions)
+
+// set max number of retries (0 to disable) and backoff limits
+func WithRetries(maxRetries int, minWait, maxWait time.Duration) Option {
+	return func(o *clientOptions) {
+		o.maxRetries = maxRetries
+		o.retryMinWait = minWait
+		o.retryMaxWait = maxWait
+	}
+}
+
+// set rate limiting algorithm and its parameters
+func WithRateLimit(rl RateLimit) Option {
+	return func(o *clientOptions) {
+		o.rateLimit = rl
+	}
+}
+
+// act on behalf of reseller sub-account: set X-Shopper-Id for all requests
+func WithShopperID(shopperID string) Option {
+	return func(o *clientOptions) {
+		o.shopperID = shopperID
+	}
+}
+
+type shopperIDKey struct{}
+
+// set shopper ID for requests with this context, overriding client default
+func ContextWithShopperID(ctx context.Context, shopperID string) context.Context {
+	return context.WithValue(ctx, shopperIDKey{}, shopperID)
+}
+
+// shopper ID set with ContextWithShopperID, empty if none
+func ShopperIDFromContext(ctx context.Context) string {
+	shopperID, _ := ctx.Value(shopperIDKey{}).(string)
+	return shopperID
+}
+
+func NewClient(apiURL string, key string, secret string, opts ...Option) (*Client, error) {
+	options := clientOptions{
+		maxRetries:   RETRY_MAX,
+		retryMinWait: RETRY_MIN_WAIT,
+		retryMaxWait: RETRY_MAX_WAIT,
+		rateLimit:    DefaultRateLimit,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
 	// t := http.DefaultTransport.(*http.Transport).Clone()
 	httpTransport := &http.Transport{
 		DialContext: (&net.Dialer{
@@ -50,22 +112,31 @@ func NewClient(apiURL string, key string, secret string) (*Client, error) {
 		TLSHandshakeTimeout:   HTTP_TIMEOUT * time.Second,
 		ResponseHeaderTimeout: HTTP_TIMEOUT * time.Second,
 	}
-	// TODO: mb make it pluggable as a parameter
-	// rateLimiter, err := ratelimiter.NewBucketRL(HTTP_RPS, HTTP_BURST)
-	rateLimiter, err := ratelimiter.NewWindowRL(HTTP_RATE_WINDOW, HTTP_RATE_RPW)
+	rateLimiter, err := newRateLimiter(options.rateLimit, key)
 	if err != nil {
 		return nil, errors.Wrap(err, "cannot create rate limiter")
 	}
-	httpClient := http.Client{
-		Transport: &rateLimitedHTTPTransport{
+	var transport http.RoundTripper = httpTransport
+	if rateLimiter != nil {
+		transport = &rateLimitedHTTPTransport{
 			limiter: rateLimiter,
 			next:    httpTransport,
+		}
+	}
+	httpClient := http.Client{
+		// retries are rate-limited too
+		Transport: &retryHTTPTransport{
+			maxRetries: options.maxRetries,
+			minWait:    options.retryMinWait,
+			maxWait:    options.retryMaxWait,
+			next:       transport,
 		},
 	}
 	return &Client{
 		apiURL:     apiURL,
 		key:        key,
 		secret:     secret,
+		shopperID:  options.shopperID,
 		httpClient: httpClient,
 	}, nil
 }
@@ -85,14 +156,12 @@ type apiDNSRecord struct {
 	Weight   uint16 `json:"weight,omitempty"`
 }
 
-type apiErrorResponce struct {
-	Error   string `json:"code"`    // like "INVALID_VALUE_ENUM"
-	Message string `json:"message"` // like "type not any of: A, ..."
-}
-
-func (c Client) makeRecordsRequest(ctx context.Context, path string, method string, body io.Reader) (*http.Response, error) {
+func (c Client) makeRecordsRequest(ctx context.Context, path string, query url.Values, method string, body io.Reader) (*http.Response, error) {
 
 	requestURL, _ := url.JoinPath(c.apiURL, DOMAINS_URL, path)
+	if len(query) > 0 {
+		requestURL += "?" + query.Encode()
+	}
 
 	req, err := http.NewRequestWithContext(ctx, method, requestURL, body)
 	if err != nil {
@@ -102,6 +171,13 @@ func (c Client) makeRecordsRequest(ctx context.Context, path string, method stri
 	req.Header.Add("Content-Type", "application/json")
 	req.Header.Add("Accept", "application/json")
 	req.Header.Add("Authorization", fmt.Sprintf("sso-key %s:%s", c.key, c.secret))
+	shopperID := c.shopperID
+	if ctxShopperID := ShopperIDFromContext(ctx); ctxShopperID != "" {
+		shopperID = ctxShopperID
+	}
+	if shopperID != "" {
+		req.Header.Add("X-Shopper-Id", shopperID)
+	}
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
@@ -110,34 +186,54 @@ func (c Client) makeRecordsRequest(ctx context.Context, path string, method stri
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		defer resp.Body.Close()
-		var errRes apiErrorResponce
-		if err = json.NewDecoder(resp.Body).Decode(&errRes); err == nil {
-			return nil, errors.New("api error: " + errRes.Message)
+		apiErr := &APIError{}
+		if err = json.NewDecoder(resp.Body).Decode(apiErr); err != nil {
+			// not json: only status is known
+			apiErr = &APIError{}
 		}
-		return nil, fmt.Errorf("bad http reply status (%s)", resp.Status)
+		apiErr.StatusCode = resp.StatusCode
+		apiErr.RequestID = resp.Header.Get("X-Request-Id")
+		return nil, apiErr
 	}
 	return resp, nil
 }
 
 // in real API call
-// - name and then type are optional (to get all records of type or just all records)
-// - there are also "offset" and "limit" in query params for paged output
+//   - name and then type are optional (to get all records of type or just all records)
+//   - output is paged with "offset" and "limit" query params: pages are requested
+//     until a short one is returned (each one is a separate rate-limited request);
+//     a page starting with the same record as the previous one (offset is
+//     ignored) or too many pages are errors, instead of looping forever
 func (c Client) GetRecords(ctx context.Context, rDomain model.DNSDomain,
 	rType model.DNSRecordType, rName model.DNSRecordName) ([]model.DNSRecord, error) {
 
 	rPath, _ := url.JoinPath(string(rDomain), "records", string(rType), string(rName))
 
-	resp, err := c.makeRecordsRequest(ctx, rPath, http.MethodGet, nil)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
 	var responceRecords []apiDNSRecord
-	err = json.NewDecoder(resp.Body).Decode(&responceRecords)
-	if err != nil {
-		return nil, errors.Wrap(err, "cannot decode json reply")
+	var prevPage []apiDNSRecord
+	for offset, numPages := 0, 0; ; numPages++ {
+		if numPages == GET_MAX_PAGES {
+			return nil, errors.Errorf("too many records: more than %d pages of %d",
+				GET_MAX_PAGES, GET_PAGE_SIZE)
+		}
+		page, err := c.getRecordsPage(ctx, rPath, offset, GET_PAGE_SIZE)
+		if err != nil {
+			return nil, err
+		}
+		if len(page) > 0 && len(prevPage) > 0 && page[0] == prevPage[0] {
+			return nil, errors.Errorf("paging is not working: page at offset %d "+
+				"is the same as previous one", offset)
+		}
+		responceRecords = append(responceRecords, page...)
+		// short page is the last one; longer than requested means that
+		// paging is not supported, and so everything is already here
+		if len(page) != GET_PAGE_SIZE {
+			break
+		}
+		prevPage = page
+		offset += len(page)
 	}
+
 	res := make([]model.DNSRecord, 0, len(responceRecords))
 	for _, rr := range responceRecords {
 		res = append(res, model.DNSRecord{
@@ -155,6 +251,26 @@ func (c Client) GetRecords(ctx context.Context, rDomain model.DNSDomain,
 	return res, nil
 }
 
+// get one page of records, starting from offset
+func (c Client) getRecordsPage(ctx context.Context, rPath string, offset, limit int) ([]apiDNSRecord, error) {
+	query := url.Values{}
+	query.Set("offset", strconv.Itoa(offset))
+	query.Set("limit", strconv.Itoa(limit))
+
+	resp, err := c.makeRecordsRequest(ctx, rPath, query, http.MethodGet, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var responceRecords []apiDNSRecord
+	err = json.NewDecoder(resp.Body).Decode(&responceRecords)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot decode json reply")
+	}
+	return responceRecords, nil
+}
+
 // create (add) records for rType+rName
 // existing are staying in place; there could be several records for type + name (eg MX)
 func (c Client) AddRecords(ctx context.Context, rDomain model.DNSDomain,
@@ -187,7 +303,7 @@ func (c Client) AddRecords(ctx context.Context, rDomain model.DNSDomain,
 		return errors.Wrap(err, "cannot marshal json")
 	}
 
-	resp, err := c.makeRecordsRequest(ctx, rPath, http.MethodPatch, bytes.NewReader(jsonData))
+	resp, err := c.makeRecordsRequest(ctx, rPath, nil, http.MethodPatch, bytes.NewReader(jsonData))
 	if resp != nil {
 		defer resp.Body.Close()
 	}
@@ -230,7 +346,7 @@ func (c Client) SetRecords(ctx context.Context, rDomain model.DNSDomain,
 		return errors.Wrap(err, "cannot marshal json")
 	}
 
-	resp, err := c.makeRecordsRequest(ctx, rPath, http.MethodPut, bytes.NewReader(jsonData))
+	resp, err := c.makeRecordsRequest(ctx, rPath, nil, http.MethodPut, bytes.NewReader(jsonData))
 	if resp != nil {
 		defer resp.Body.Close()
 	}
@@ -246,7 +362,7 @@ func (c Client) DelRecords(ctx context.Context, rDomain model.DNSDomain, rType m
 
 	rPath, _ := url.JoinPath(string(rDomain), "records", string(rType), string(rName))
 
-	resp, err := c.makeRecordsRequest(ctx, rPath, http.MethodDelete, nil)
+	resp, err := c.makeRecordsRequest(ctx, rPath, nil, http.MethodDelete, nil)
 	if resp != nil {
 		defer resp.Body.Close()
 	}
@@ -7,13 +7,22 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
 	"github.com/veksh/terraform-provider-godaddy-dns/internal/model"
+	"github.com/veksh/terraform-provider-godaddy-dns/libs/ratelimiter"
 )
 
 const HTTPReplySometingCN = `
@@ -107,21 +116,83 @@ func TestGetRecords_RateLimit(t *testing.T) {
 		}))
 	defer ts.Close()
 
-	c, err := NewClient(ts.URL, "dummyAPIKey", "dummyAPISecret")
+	// default limit on fake time: 60 requests, then wait for the next window
+	clock := ratelimiter.NewFakeClock(time.Now())
+	rl := DefaultRateLimit
+	rl.Clock = clock
+	c, err := NewClient(ts.URL, "dummyAPIKey", "dummyAPISecret", WithRateLimit(rl))
 	if err != nil {
 		t.Fatal(err)
 	}
-	// not good to rely on params, but cumbersome to make it configurable :)
-	start := time.Now()
-	for i := 0; i < 61; i++ {
+	for i := 0; i < 60; i++ {
 		_, err = c.GetRecords(context.Background(), "test.com", "CNAME", "cn")
 		if err != nil {
 			t.Fatal(err)
 		}
 	}
-	elapsed := time.Since(start)
-	if elapsed < time.Second {
-		t.Error("too little time elapsed for 61 request")
+	done := make(chan error)
+	go func() {
+		_, err := c.GetRecords(context.Background(), "test.com", "CNAME", "cn")
+		done <- err
+	}()
+	if !clock.BlockUntil(1, 5*time.Second) {
+		t.Fatal("request is not waiting for rate limit")
+	}
+	clock.Advance(59 * time.Second)
+	select {
+	case <-done:
+		t.Fatal("61st request is not delayed until the next window")
+	default:
+	}
+	clock.Advance(time.Second)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGetRecords_SetsShopperHeader(t *testing.T) {
+	t.Parallel()
+	var shopperHeaders []string
+	ts := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			shopperHeaders = append(shopperHeaders, r.Header.Get("X-Shopper-Id"))
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintln(w, HTTPReplySometingCN)
+		}))
+	defer ts.Close()
+
+	c, err := NewClient(ts.URL, "dummyAPIKey", "dummyAPISecret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs, err := NewClient(ts.URL, "dummyAPIKey", "dummyAPISecret", WithShopperID("12345"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, get := range []func() error{
+		// no header by default
+		func() error {
+			_, err := c.GetRecords(context.Background(), "test.com", "CNAME", "cn")
+			return err
+		},
+		// client default
+		func() error {
+			_, err := cs.GetRecords(context.Background(), "test.com", "CNAME", "cn")
+			return err
+		},
+		// override from context
+		func() error {
+			_, err := cs.GetRecords(ContextWithShopperID(context.Background(), "67890"), "test.com", "CNAME", "cn")
+			return err
+		},
+	} {
+		if err := get(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	shopperHeadersWant := []string{"", "12345", "67890"}
+	if !cmp.Equal(shopperHeadersWant, shopperHeaders) {
+		t.Error("shopper header mismatch:", cmp.Diff(shopperHeadersWant, shopperHeaders))
 	}
 }
 
@@ -142,8 +213,8 @@ func TestSetRecords_ProperFormat(t *testing.T) {
 				return
 			}
 			if req[0] != expected[0] {
-				reply := apiErrorResponce{
-					Error: "BAD_FORMAT",
+				reply := APIError{
+					Code: "BAD_FORMAT",
 					Message: fmt.Sprintf("unexpected request: want %v, got %v",
 						expected, req),
 				}
@@ -171,3 +242,631 @@ func TestSetRecords_ProperFormat(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+// serve numRecs A records, honouring offset (unless ignored) and limit
+func pagedRecordsHandler(numRecs int, numRequests *int, ignoreOffset bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		*numRequests += 1
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		if ignoreOffset {
+			offset = 0
+		}
+		limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+		if err != nil || limit <= 0 {
+			http.Error(w, "bad limit", http.StatusBadRequest)
+			return
+		}
+		page := []apiDNSRecord{}
+		for i := offset; i < numRecs && i < offset+limit; i++ {
+			page = append(page, apiDNSRecord{
+				Type: "A",
+				Name: fmt.Sprintf("host%d", i),
+				Data: "1.1.1.1",
+				TTL:  3600,
+			})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(page) //nolint:errcheck
+	}
+}
+
+func TestGetRecords_Paging(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		numRecs      int
+		wantRequests int
+	}{
+		{0, 1},
+		{GET_PAGE_SIZE - 1, 1},
+		{GET_PAGE_SIZE, 2},
+		{GET_PAGE_SIZE*2 + 10, 3},
+	}
+	for _, tt := range tests {
+		numRequests := 0
+		ts := httptest.NewServer(pagedRecordsHandler(tt.numRecs, &numRequests, false))
+		c, err := NewClient(ts.URL, "dummyAPIKey", "dummyAPISecret")
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := c.GetRecords(context.Background(), "test.com", "", "")
+		ts.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != tt.numRecs {
+			t.Errorf("%d records: got %d", tt.numRecs, len(got))
+		}
+		if numRequests != tt.wantRequests {
+			t.Errorf("%d records: want %d requests, got %d", tt.numRecs, tt.wantRequests, numRequests)
+		}
+		// must be in order, without duplicates
+		for i, rec := range got {
+			if rec.Name != model.DNSRecordName(fmt.Sprintf("host%d", i)) {
+				t.Errorf("%d records: unexpected record %d: %v", tt.numRecs, i, rec)
+				break
+			}
+		}
+	}
+}
+
+// server ignores paging and returns everything at once: must not loop
+func TestGetRecords_PagingNotSupported(t *testing.T) {
+	t.Parallel()
+	numRequests := 0
+	ts := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			numRequests += 1
+			page := make([]apiDNSRecord, GET_PAGE_SIZE+1)
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(page) //nolint:errcheck
+		}))
+	defer ts.Close()
+
+	c, err := NewClient(ts.URL, "dummyAPIKey", "dummyAPISecret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := c.GetRecords(context.Background(), "test.com", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != GET_PAGE_SIZE+1 || numRequests != 1 {
+		t.Errorf("want %d records in 1 request, got %d in %d", GET_PAGE_SIZE+1, len(got), numRequests)
+	}
+}
+
+// server ignores offset and always returns full page: must not loop
+func TestGetRecords_PagingOffsetIgnored(t *testing.T) {
+	t.Parallel()
+	numRequests := 0
+	ts := httptest.NewServer(pagedRecordsHandler(GET_PAGE_SIZE, &numRequests, true))
+	defer ts.Close()
+
+	c, err := NewClient(ts.URL, "dummyAPIKey", "dummyAPISecret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = c.GetRecords(context.Background(), "test.com", "", ""); err == nil {
+		t.Error("want error for repeating pages")
+	}
+	if numRequests != 2 {
+		t.Errorf("want 2 requests, got %d", numRequests)
+	}
+}
+
+// server returns full pages forever: must stop after max pages
+func TestGetRecords_PagingTooManyPages(t *testing.T) {
+	t.Parallel()
+	numRequests := 0
+	ts := httptest.NewServer(pagedRecordsHandler(GET_PAGE_SIZE*(GET_MAX_PAGES+1), &numRequests, false))
+	defer ts.Close()
+
+	c, err := NewClient(ts.URL, "dummyAPIKey", "dummyAPISecret", WithRateLimit(RateLimit{Algorithm: RL_NONE}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = c.GetRecords(context.Background(), "test.com", "", ""); err == nil {
+		t.Error("want error for too many pages")
+	}
+	if numRequests != GET_MAX_PAGES {
+		t.Errorf("want %d requests, got %d", GET_MAX_PAGES, numRequests)
+	}
+}
+
+// reply with given status and body for the first failures requests, then normally
+func flakyHandler(failures int, status int, header http.Header, body string, numRequests *atomic.Int32) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if int(numRequests.Add(1)) <= failures {
+			for k, v := range header {
+				w.Header()[k] = v
+			}
+			w.WriteHeader(status)
+			fmt.Fprintln(w, body)
+			return
+		}
+		if r.Method == http.MethodGet {
+			fmt.Fprintln(w, HTTPReplySometingCN)
+		}
+	}
+}
+
+func TestRetry_RetriesThrottled(t *testing.T) {
+	t.Parallel()
+	var numRequests atomic.Int32
+	ts := httptest.NewServer(flakyHandler(2, http.StatusTooManyRequests,
+		http.Header{"Retry-After": {"0"}},
+		`{"code": "TOO_MANY_REQUESTS", "message": "slow down"}`, &numRequests))
+	defer ts.Close()
+
+	c, err := NewClient(ts.URL, "dummyAPIKey", "dummyAPISecret",
+		WithRetries(3, time.Millisecond, 10*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.GetRecords(context.Background(), "test.com", "CNAME", "cn")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if numRequests.Load() != 3 {
+		t.Errorf("want 3 requests, got %d", numRequests.Load())
+	}
+}
+
+func TestRetry_ResendsBodyOnPatch(t *testing.T) {
+	t.Parallel()
+	var numRequests atomic.Int32
+	var lastBody []byte
+	ts := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			lastBody, _ = io.ReadAll(r.Body)
+			if numRequests.Add(1) == 1 {
+				w.WriteHeader(http.StatusTooManyRequests)
+				fmt.Fprintln(w, `{"code": "TOO_MANY_REQUESTS", "message": "slow down", "retryAfterSec": 0}`)
+			}
+		}))
+	defer ts.Close()
+
+	c, err := NewClient(ts.URL, "dummyAPIKey", "dummyAPISecret",
+		WithRetries(3, time.Millisecond, 10*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.AddRecords(context.Background(), "test.com",
+		[]model.DNSRecord{{Type: "CNAME", Name: "cn", Data: "other.com", TTL: 600}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if numRequests.Load() != 2 {
+		t.Errorf("want 2 requests, got %d", numRequests.Load())
+	}
+	if !strings.Contains(string(lastBody), "other.com") {
+		t.Errorf("body not resent on retry: %q", lastBody)
+	}
+}
+
+func TestRetry_NoRetryForPatchOnServerError(t *testing.T) {
+	t.Parallel()
+	var numRequests atomic.Int32
+	ts := httptest.NewServer(flakyHandler(1, http.StatusServiceUnavailable, nil,
+		`{"code": "UNAVAILABLE", "message": "try later"}`, &numRequests))
+	defer ts.Close()
+
+	c, err := NewClient(ts.URL, "dummyAPIKey", "dummyAPISecret",
+		WithRetries(3, time.Millisecond, 10*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.AddRecords(context.Background(), "test.com",
+		[]model.DNSRecord{{Type: "CNAME", Name: "cn", Data: "other.com", TTL: 600}})
+	if err == nil {
+		t.Fatal("got no error for failed PATCH")
+	}
+	if numRequests.Load() != 1 {
+		t.Errorf("want 1 request, got %d", numRequests.Load())
+	}
+
+	// GET is retried
+	numRequests.Store(0)
+	_, err = c.GetRecords(context.Background(), "test.com", "CNAME", "cn")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if numRequests.Load() != 2 {
+		t.Errorf("want 2 requests, got %d", numRequests.Load())
+	}
+}
+
+func TestRetry_GivesUpAfterMaxRetries(t *testing.T) {
+	t.Parallel()
+	var numRequests atomic.Int32
+	ts := httptest.NewServer(flakyHandler(100, http.StatusBadGateway, nil, `bad gateway`, &numRequests))
+	defer ts.Close()
+
+	c, err := NewClient(ts.URL, "dummyAPIKey", "dummyAPISecret",
+		WithRetries(2, time.Millisecond, 10*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.GetRecords(context.Background(), "test.com", "CNAME", "cn")
+	if err == nil {
+		t.Fatal("got no error after retries")
+	}
+	if numRequests.Load() != 3 {
+		t.Errorf("want 3 requests, got %d", numRequests.Load())
+	}
+}
+
+func TestRetry_RespectsDeadline(t *testing.T) {
+	t.Parallel()
+	var numRequests atomic.Int32
+	ts := httptest.NewServer(flakyHandler(100, http.StatusTooManyRequests,
+		http.Header{"Retry-After": {"30"}},
+		`{"code": "TOO_MANY_REQUESTS", "message": "slow down"}`, &numRequests))
+	defer ts.Close()
+
+	c, err := NewClient(ts.URL, "dummyAPIKey", "dummyAPISecret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	start := time.Now()
+	_, err = c.GetRecords(ctx, "test.com", "CNAME", "cn")
+	if err == nil {
+		t.Fatal("got no error for throttled request")
+	}
+	if time.Since(start) > time.Second {
+		t.Error("waited for retry beyond context deadline")
+	}
+	if numRequests.Load() != 1 {
+		t.Errorf("want 1 request, got %d", numRequests.Load())
+	}
+}
+
+func TestRetry_GivesUpOnLongServerWait(t *testing.T) {
+	t.Parallel()
+	var numRequests atomic.Int32
+	ts := httptest.NewServer(flakyHandler(100, http.StatusTooManyRequests,
+		http.Header{"Retry-After": {"86400"}},
+		`{"code": "TOO_MANY_REQUESTS", "message": "quota exceeded"}`, &numRequests))
+	defer ts.Close()
+
+	c, err := NewClient(ts.URL, "dummyAPIKey", "dummyAPISecret",
+		WithRetries(3, time.Millisecond, 10*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	_, err = c.GetRecords(context.Background(), "test.com", "CNAME", "cn")
+	if err == nil {
+		t.Fatal("got no error for throttled request")
+	}
+	if time.Since(start) > time.Second {
+		t.Error("waited for retry beyond max wait")
+	}
+	if numRequests.Load() != 1 {
+		t.Errorf("want 1 request, got %d", numRequests.Load())
+	}
+}
+
+func TestAddRecords_ReturnsAPIError(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("X-Request-Id", "req-123")
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			fmt.Fprintln(w, `{
+				"code": "INVALID_BODY",
+				"message": "Request body doesn't fulfill schema",
+				"fields": [{
+					"path": "records[0].ttl",
+					"code": "UNEXPECTED_TYPE",
+					"message": "is less than minimum of 600"
+				}]
+			}`)
+		}))
+	defer ts.Close()
+
+	c, err := NewClient(ts.URL, "dummyAPIKey", "dummyAPISecret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.AddRecords(context.Background(), "test.com",
+		[]model.DNSRecord{{Type: "CNAME", Name: "cn", Data: "other.com", TTL: 60}})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("want APIError, got %v", err)
+	}
+	want := &APIError{
+		StatusCode: http.StatusUnprocessableEntity,
+		Code:       ERR_INVALID_BODY,
+		Message:    "Request body doesn't fulfill schema",
+		Fields: []APIErrorField{{
+			Path:    "records[0].ttl",
+			Code:    "UNEXPECTED_TYPE",
+			Message: "is less than minimum of 600",
+		}},
+		RequestID: "req-123",
+	}
+	if !cmp.Equal(want, apiErr) {
+		t.Error(cmp.Diff(want, apiErr))
+	}
+	if apiErr.Fields[0].Name() != "ttl" {
+		t.Errorf("want field name ttl, got %q", apiErr.Fields[0].Name())
+	}
+}
+
+func TestGetRecords_ReturnsAPIErrorForNonJSON(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "no such thing", http.StatusNotFound)
+		}))
+	defer ts.Close()
+
+	c, err := NewClient(ts.URL, "dummyAPIKey", "dummyAPISecret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.GetRecords(context.Background(), "test.com", "CNAME", "cn")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("want APIError, got %v", err)
+	}
+	if apiErr.StatusCode != http.StatusNotFound || apiErr.Code != "" {
+		t.Errorf("unexpected error: %#v", apiErr)
+	}
+}
+
+func TestGetRecords_ConfigurableRateLimit(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintln(w, HTTPReplySometingCN)
+		}))
+	// parallel subtests finish after function returns
+	t.Cleanup(ts.Close)
+
+	tests := []struct {
+		name      string
+		rateLimit RateLimit
+		// requests let through at once, wait for the next one (none if 0)
+		immediate int
+		wait      time.Duration
+	}{
+		{
+			name:      "none",
+			rateLimit: RateLimit{Algorithm: RL_NONE},
+			immediate: 100,
+		},
+		{
+			// 2 immediately, then 1 per 100ms
+			name:      "bucket",
+			rateLimit: RateLimit{Algorithm: RL_BUCKET, Requests: 10, Period: time.Second, Burst: 2},
+			immediate: 2,
+			wait:      100 * time.Millisecond,
+		},
+		{
+			// 3 immediately, then wait for next window
+			name:      "window",
+			rateLimit: RateLimit{Algorithm: RL_WINDOW, Requests: 3, Period: 200 * time.Millisecond},
+			immediate: 3,
+			wait:      200 * time.Millisecond,
+		},
+		{
+			// evenly spaced, 100ms apart
+			name:      "adaptive",
+			rateLimit: RateLimit{Algorithm: RL_ADAPTIVE, Requests: 10, Period: time.Second},
+			immediate: 1,
+			wait:      100 * time.Millisecond,
+		},
+		{
+			// 3 immediately, then wait for first to expire
+			name:      "sliding",
+			rateLimit: RateLimit{Algorithm: RL_SLIDING, Requests: 3, Period: 200 * time.Millisecond},
+			immediate: 3,
+			wait:      200 * time.Millisecond,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			clock := ratelimiter.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+			rl := tt.rateLimit
+			rl.Clock = clock
+			c, err := NewClient(ts.URL, "dummyAPIKey", "dummyAPISecret", WithRateLimit(rl))
+			if err != nil {
+				t.Fatal(err)
+			}
+			get := func() chan error {
+				done := make(chan error, 1)
+				go func() {
+					_, err := c.GetRecords(context.Background(), "test.com", "CNAME", "cn")
+					done <- err
+				}()
+				return done
+			}
+			waitDone := func(done chan error) {
+				t.Helper()
+				select {
+				case err := <-done:
+					if err != nil {
+						t.Fatal(err)
+					}
+				case <-time.After(5 * time.Second):
+					t.Fatal("request is blocked")
+				}
+			}
+
+			for i := 0; i < tt.immediate; i++ {
+				waitDone(get())
+			}
+			if tt.wait == 0 {
+				return
+			}
+			done := get()
+			if !clock.BlockUntil(1, 5*time.Second) {
+				t.Fatal("request is not waiting for rate limit")
+			}
+			clock.Advance(tt.wait - time.Millisecond)
+			// still waiting: timer is re-armed or not fired yet
+			if !clock.BlockUntil(1, 5*time.Second) {
+				t.Fatal("request is not waiting for rate limit")
+			}
+			select {
+			case err := <-done:
+				t.Fatalf("request let through too early (err: %v)", err)
+			default:
+			}
+			clock.Advance(time.Millisecond)
+			waitDone(done)
+		})
+	}
+}
+
+func TestGetRecords_SharedRateLimit(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintln(w, HTTPReplySometingCN)
+		}))
+	defer ts.Close()
+
+	// two clients (like two provider processes) share 4 requests per 300ms
+	rl := RateLimit{
+		Algorithm: RL_FILE,
+		Requests:  4,
+		Period:    300 * time.Millisecond,
+		File:      filepath.Join(t.TempDir(), "test.ratelimit"),
+	}
+	clients := []*Client{}
+	for i := 0; i < 2; i++ {
+		c, err := NewClient(ts.URL, "dummyAPIKey", "dummyAPISecret", WithRateLimit(rl))
+		if err != nil {
+			t.Fatal(err)
+		}
+		clients = append(clients, c)
+	}
+	start := time.Now()
+	var wg sync.WaitGroup
+	for _, c := range clients {
+		wg.Add(1)
+		go func(c *Client) {
+			defer wg.Done()
+			for i := 0; i < 3; i++ {
+				if _, err := c.GetRecords(context.Background(), "test.com", "CNAME", "cn"); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}(c)
+	}
+	wg.Wait()
+	// 4 immediately, 2 more after first ones expire
+	if elapsed := time.Since(start); elapsed < 250*time.Millisecond || elapsed > 2*time.Second {
+		t.Errorf("6 requests took %s, want about 300ms", elapsed)
+	}
+}
+
+// records feedback passed to limiter
+type feedbackRecorder struct {
+	mu       sync.Mutex
+	feedback []ratelimiter.Feedback
+}
+
+func (r *feedbackRecorder) Wait()                             {}
+func (r *feedbackRecorder) WaitCtx(ctx context.Context) error { return nil }
+func (r *feedbackRecorder) Stats() ratelimiter.Stats          { return ratelimiter.Stats{} }
+func (r *feedbackRecorder) Feedback(fb ratelimiter.Feedback) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.feedback = append(r.feedback, fb)
+}
+
+func TestRateLimitedTransport_PassesFeedback(t *testing.T) {
+	t.Parallel()
+	numCalls := 0
+	ts := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			numCalls++
+			switch numCalls {
+			case 1:
+				w.Header().Set("Retry-After", "3")
+				w.WriteHeader(http.StatusTooManyRequests)
+			case 2:
+				w.Header().Set("X-RateLimit-Remaining", "5")
+				w.Header().Set("X-RateLimit-Reset", "20")
+			}
+			fmt.Fprintln(w, "[]")
+		}))
+	defer ts.Close()
+
+	rec := &feedbackRecorder{}
+	hc := &http.Client{Transport: &rateLimitedHTTPTransport{limiter: rec, next: http.DefaultTransport}}
+	for i := 0; i < 3; i++ {
+		resp, err := hc.Get(ts.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	want := []ratelimiter.Feedback{
+		{Throttled: true, RetryAfter: 3 * time.Second, Remaining: -1},
+		{Remaining: 5, Reset: 20 * time.Second},
+		{Remaining: -1},
+	}
+	if !cmp.Equal(want, rec.feedback) {
+		t.Error(cmp.Diff(want, rec.feedback))
+	}
+}
+
+func TestNewClient_BadRateLimit(t *testing.T) {
+	t.Parallel()
+	for _, rl := range []RateLimit{
+		{Algorithm: "leaky", Requests: 1, Period: time.Second},
+		{Algorithm: RL_WINDOW, Requests: 0, Period: time.Second},
+		{Algorithm: RL_BUCKET, Requests: 10, Period: 0},
+		{Algorithm: RL_FILE, Requests: 10, Period: time.Second, File: "/nonexistent/dir/test.ratelimit"},
+	} {
+		if _, err := NewClient("http://localhost", "dummyAPIKey", "dummyAPISecret", WithRateLimit(rl)); err == nil {
+			t.Errorf("no error for bad rate limit %v", rl)
+		}
+	}
+}
+
+// state file is per user: temp dir is shared, file is accessible only by owner
+func TestDefaultRateLimitFile(t *testing.T) {
+	t.Parallel()
+	path := defaultRateLimitFile("dummyAPIKey")
+	if path != defaultRateLimitFile("dummyAPIKey") {
+		t.Error("want the same file for the same key")
+	}
+	if path == defaultRateLimitFile("otherAPIKey") {
+		t.Error("want different files for different keys")
+	}
+	if uid := fmt.Sprintf("-%d-", os.Getuid()); !strings.Contains(filepath.Base(path), uid) {
+		t.Errorf("want uid %q in file name, got %q", uid, path)
+	}
+}
+
+// like state file left by another user: error, not a hang or panic
+func TestNewClient_RateLimitFileNoAccess(t *testing.T) {
+	t.Parallel()
+	if os.Getuid() == 0 {
+		t.Skip("root has access to any file")
+	}
+	path := filepath.Join(t.TempDir(), "test.ratelimit")
+	if err := os.WriteFile(path, nil, 0o000); err != nil {
+		t.Fatal(err)
+	}
+	rl := RateLimit{Algorithm: RL_FILE, Requests: 10, Period: time.Second, File: path}
+	_, err := NewClient("http://localhost", "dummyAPIKey", "dummyAPISecret", WithRateLimit(rl))
+	if !errors.Is(err, os.ErrPermission) {
+		t.Errorf("want permission error for inaccessible state file, got %v", err)
+	}
+}
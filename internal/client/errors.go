@@ -0,0 +1,61 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// some of GoDaddy API error codes, see API docs for full list
+const (
+	ERR_DUPLICATE_RECORD  = "DUPLICATE_RECORD"
+	ERR_NOT_FOUND         = "NOT_FOUND"
+	ERR_UNABLE_TO_AUTH    = "UNABLE_TO_AUTHENTICATE"
+	ERR_ACCESS_DENIED     = "ACCESS_DENIED"
+	ERR_INVALID_BODY      = "INVALID_BODY"
+	ERR_TOO_MANY_REQUESTS = "TOO_MANY_REQUESTS"
+)
+
+// validation error details for one field of request
+type APIErrorField struct {
+	Path        string `json:"path"`        // like "records[0].data"
+	PathRelated string `json:"pathRelated"` // other field involved, if any
+	Code        string `json:"code"`        // like "INVALID_VALUE"
+	Message     string `json:"message"`
+}
+
+// error returned by API: status from http reply, the rest from json body
+// (if it could be decoded), request ID from X-Request-Id header
+type APIError struct {
+	StatusCode    int             `json:"-"`
+	Code          string          `json:"code"`    // like "INVALID_VALUE_ENUM"
+	Message       string          `json:"message"` // like "type not any of: A, ..."
+	Fields        []APIErrorField `json:"fields"`
+	RequestID     string          `json:"-"`
+	RetryAfterSec int             `json:"retryAfterSec"` // for 429 (TOO_MANY_REQUESTS)
+}
+
+func (e *APIError) Error() string {
+	if e.Code == "" && e.Message == "" {
+		return fmt.Sprintf("bad http reply status (%d %s)", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "api error: %s (%s, status %d)", e.Message, e.Code, e.StatusCode)
+	for _, f := range e.Fields {
+		fmt.Fprintf(&sb, "; %s: %s", f.Path, f.Message)
+	}
+	if e.RequestID != "" {
+		fmt.Fprintf(&sb, " [request id %s]", e.RequestID)
+	}
+	return sb.String()
+}
+
+// field name (last path component without index) for validation errors,
+// like "data" for "records[0].data"
+func (f APIErrorField) Name() string {
+	name := f.Path[strings.LastIndex(f.Path, ".")+1:]
+	if i := strings.Index(name, "["); i >= 0 {
+		name = name[:i]
+	}
+	return name
+}
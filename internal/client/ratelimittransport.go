@@ -1,19 +1,133 @@
 package client
 
 import (
+	"crypto/sha256"
+	"fmt"
 	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/veksh/terraform-provider-godaddy-dns/libs/ratelimiter"
 )
 
+// rate limiting algorithms
+const (
+	RL_WINDOW   = "window"
+	RL_SLIDING  = "sliding"
+	RL_BUCKET   = "bucket"
+	RL_FILE     = "file"
+	RL_ADAPTIVE = "adaptive"
+	RL_NONE     = "none"
+)
+
+// rate limiter settings
+type RateLimit struct {
+	// one of RL_*
+	Algorithm string
+	// requests per period
+	Requests int
+	Period   time.Duration
+	// max burst size for bucket; default is Requests
+	Burst int
+	// state file shared between processes for file
+	File string
+	// time source for limiter; real time if nil (could be set for tests)
+	Clock ratelimiter.Clock
+}
+
+// default is GoDaddy limit: 60 requests per minute
+var DefaultRateLimit = RateLimit{
+	Algorithm: RL_WINDOW,
+	Requests:  HTTP_RATE_RPW,
+	Period:    HTTP_RATE_WINDOW,
+}
+
+// default state file for shared limiter: one per API key and local user, as
+// temp dir is shared and file is accessible only by its owner
+func defaultRateLimitFile(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(os.TempDir(),
+		fmt.Sprintf("terraform-provider-godaddy-dns-%d-%x.ratelimit", os.Getuid(), sum[:6]))
+}
+
+// make limiter for settings, nil if there is no limit
+func newRateLimiter(rl RateLimit, key string) (ratelimiter.Limiter, error) {
+	opts := []ratelimiter.Option{}
+	if rl.Clock != nil {
+		opts = append(opts, ratelimiter.WithClock(rl.Clock))
+	}
+	switch rl.Algorithm {
+	case RL_NONE:
+		return nil, nil
+	case RL_WINDOW, "":
+		return ratelimiter.NewWindowRL(rl.Period, rl.Requests, opts...)
+	case RL_SLIDING:
+		return ratelimiter.NewSlidingRL(rl.Period, rl.Requests, opts...)
+	case RL_BUCKET:
+		if rl.Requests <= 0 {
+			return nil, fmt.Errorf("limiter num of requests must be positive")
+		}
+		burst := rl.Burst
+		if burst == 0 {
+			burst = rl.Requests
+		}
+		return ratelimiter.NewBucketRLInterval(rl.Period/time.Duration(rl.Requests), burst, opts...)
+	case RL_ADAPTIVE:
+		return ratelimiter.NewAdaptiveRL(rl.Period, rl.Requests, opts...)
+	case RL_FILE:
+		path := rl.File
+		if path == "" {
+			path = defaultRateLimitFile(key)
+		}
+		return ratelimiter.NewFileRL(path, rl.Period, rl.Requests, opts...)
+	default:
+		return nil, fmt.Errorf("unknown rate limiting algorithm %q", rl.Algorithm)
+	}
+}
+
 type rateLimitedHTTPTransport struct {
 	limiter ratelimiter.Limiter
 	next    http.RoundTripper
 }
 
 func (t *rateLimitedHTTPTransport) RoundTrip(req *http.Request) (*http.Response, error) {
-	if err := t.limiter.WaitCtx(req.Context()); err != nil {
+	ctx := req.Context()
+	if err := t.limiter.WaitCtx(ctx); err != nil {
 		return nil, err
 	}
-	return t.next.RoundTrip(req)
+	resp, err := t.next.RoundTrip(req)
+	if fl, ok := t.limiter.(ratelimiter.FeedbackLimiter); ok && err == nil {
+		fb := responseFeedback(resp)
+		fl.Feedback(fb)
+		st := fl.Stats()
+		tflog.Debug(ctx, fmt.Sprintf("rate limit: reply %d, rate now %.3f rps (%d requests, %d throttled)",
+			resp.StatusCode, st.Rate, st.Requests, st.Throttled))
+	}
+	return resp, err
+}
+
+// rate limit signals from reply: status, Retry-After and X-RateLimit-* headers
+func responseFeedback(resp *http.Response) ratelimiter.Feedback {
+	fb := ratelimiter.Feedback{Remaining: -1}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		fb.Throttled = true
+		if wait, ok := retryAfter(resp); ok {
+			fb.RetryAfter = wait
+		}
+	}
+	if remaining, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining")); err == nil && remaining >= 0 {
+		fb.Remaining = remaining
+	}
+	// seconds until reset or reset time (unix seconds)
+	if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil && reset > 0 {
+		if reset > 1e9 {
+			fb.Reset = max(time.Until(time.Unix(reset, 0)), 0)
+		} else {
+			fb.Reset = time.Duration(reset) * time.Second
+		}
+	}
+	return fb
 }
@@ -0,0 +1,137 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/pkg/errors"
+)
+
+// retry failed requests with exponential backoff
+//   - 429 means request was rejected, so it is safe to retry anything, including PATCH
+//   - 5xx and network errors: only idempotent methods (GET, PUT, DELETE), PATCH
+//     could be already applied and repeating it will result in duplicates
+//   - wait time is taken from Retry-After header or `retryAfterSec` in error reply,
+//     if there are none, it is doubled on each attempt (with some jitter)
+//   - no retry if wait would end after context deadline, or if server asks
+//     to wait longer than maxWait (e.g. daily quota is exceeded)
+type retryHTTPTransport struct {
+	// 0 to disable retries
+	maxRetries int
+	// first backoff interval
+	minWait time.Duration
+	// cap for single wait
+	maxWait time.Duration
+	next    http.RoundTripper
+}
+
+func (t *retryHTTPTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	for attempt := 0; ; attempt++ {
+		attemptReq := req
+		if attempt > 0 && req.Body != nil {
+			// body is consumed by previous attempt
+			if req.GetBody == nil {
+				return nil, fmt.Errorf("cannot retry %s request: body is not rewindable", req.Method)
+			}
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attemptReq = req.Clone(ctx)
+			attemptReq.Body = body
+		}
+
+		resp, err := t.next.RoundTrip(attemptReq)
+		if attempt >= t.maxRetries || !isRetryable(req.Method, resp, err) {
+			return resp, err
+		}
+
+		wait := t.backoff(attempt)
+		if resp != nil {
+			if serverWait, ok := retryAfter(resp); ok {
+				if serverWait > t.maxWait {
+					tflog.Debug(ctx, fmt.Sprintf("retry: server wait %s exceeds max wait %s, giving up",
+						serverWait, t.maxWait))
+					return resp, err
+				}
+				wait = serverWait
+			}
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Now().Add(wait).After(deadline) {
+			tflog.Debug(ctx, fmt.Sprintf("retry: wait %s exceeds deadline, giving up", wait))
+			return resp, err
+		}
+		if resp != nil {
+			tflog.Debug(ctx, fmt.Sprintf("retry: got %s, attempt %d, waiting %s", resp.Status, attempt+1, wait))
+			io.Copy(io.Discard, resp.Body) //nolint:errcheck
+			resp.Body.Close()
+		} else {
+			tflog.Debug(ctx, fmt.Sprintf("retry: got error %q, attempt %d, waiting %s", err, attempt+1, wait))
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// exponential backoff with jitter: [0.5, 1) of minWait * 2^attempt, capped by maxWait
+func (t *retryHTTPTransport) backoff(attempt int) time.Duration {
+	wait := t.minWait << attempt
+	if wait > t.maxWait || wait <= 0 {
+		wait = t.maxWait
+	}
+	return wait/2 + time.Duration(rand.Int63n(int64(wait/2)+1))
+}
+
+func isRetryable(method string, resp *http.Response, err error) bool {
+	idempotent := method == http.MethodGet || method == http.MethodHead ||
+		method == http.MethodPut || method == http.MethodDelete
+	if err != nil {
+		// no point in retrying if cancelled
+		return idempotent && !errors.Is(err, context.Canceled) &&
+			!errors.Is(err, context.DeadlineExceeded)
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	return idempotent && resp.StatusCode >= 500
+}
+
+// wait time requested by server: Retry-After header (seconds or http date)
+// or `retryAfterSec` field of GoDaddy error reply (body is preserved)
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	if h := resp.Header.Get("Retry-After"); h != "" {
+		if secs, err := strconv.Atoi(h); err == nil && secs >= 0 {
+			return time.Duration(secs) * time.Second, true
+		}
+		if when, err := http.ParseTime(h); err == nil {
+			return max(time.Until(when), 0), true
+		}
+	}
+	if resp.Body == nil {
+		return 0, false
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return 0, false
+	}
+	var errRes APIError
+	if json.Unmarshal(body, &errRes) == nil && errRes.RetryAfterSec > 0 {
+		return time.Duration(errRes.RetryAfterSec) * time.Second, true
+	}
+	return 0, false
+}
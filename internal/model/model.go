@@ -2,7 +2,13 @@
 
 package model
 
-import "context"
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
 
 type DNSDomain string
 
@@ -19,6 +25,7 @@ type DNSRecordSRVPort uint16
 const (
 	REC_A     = DNSRecordType("A")
 	REC_AAAA  = DNSRecordType("AAAA")
+	REC_CAA   = DNSRecordType("CAA")
 	REC_CNAME = DNSRecordType("CNAME")
 	REC_MX    = DNSRecordType("MX")
 	REC_NS    = DNSRecordType("NS")
@@ -58,6 +65,8 @@ type DNSUpdateRecord struct {
 //     could point to the same host in "data", but lets think that it is a perversion
 //     and replace it with one record
 //   - and SRV same if Protocol, Port, Service and Data are matched
+//   - CAA are matched by tag + value, flags are just an attribute
+//   - data is compared in normalized form (see NormalizeData)
 func (r DNSRecord) SameKey(r1 DNSRecord) bool {
 	if r.Type != r1.Type || r.Name != r1.Name {
 		return false
@@ -67,10 +76,18 @@ func (r DNSRecord) SameKey(r1 DNSRecord) bool {
 	}
 	if r.Type == REC_SRV {
 		return r.Protocol == r1.Protocol && r.Service == r1.Service &&
-			r.Port == r1.Port && r.Data == r1.Data
+			r.Port == r1.Port && EquivalentData(r.Type, r.Data, r1.Data)
+	}
+	if r.Type == REC_CAA {
+		caa, err := ParseCAAData(r.Data)
+		caa1, err1 := ParseCAAData(r1.Data)
+		if err == nil && err1 == nil {
+			return caa.SameKey(caa1)
+		}
+		// malformed: fall back to comparing as is
 	}
 	// TXT, MX, NS, A, AAAA
-	return r.Data == r1.Data
+	return EquivalentData(r.Type, r.Data, r1.Data)
 }
 
 // convert DNSRecord to update format (dropping 2 first fields)
@@ -92,6 +109,45 @@ func (t DNSRecordType) IsSingleValue() bool {
 	return t == REC_CNAME
 }
 
+// CAA record data, in form of `<flags> <tag> "<value>"`, like `0 issue "letsencrypt.org"`
+type CAAData struct {
+	Flags uint8  // 0 or 128 (issuer critical)
+	Tag   string // issue, issuewild, iodef etc
+	Value string // CA domain name or report URL, unquoted
+}
+
+// tag is ascii letters and digits, see RFC 8659
+var caaTagRe = regexp.MustCompile(`^[a-zA-Z0-9]+$`)
+
+// parse CAA data into flags, tag and value; value could be quoted or not
+func ParseCAAData(d DNSRecordData) (CAAData, error) {
+	fields := strings.SplitN(strings.TrimSpace(string(d)), " ", 3)
+	if len(fields) != 3 {
+		return CAAData{}, fmt.Errorf("CAA data must be in `<flags> <tag> \"<value>\"` format, got %q", d)
+	}
+	flags, err := strconv.ParseUint(fields[0], 10, 8)
+	if err != nil {
+		return CAAData{}, fmt.Errorf("CAA flags must be a number in 0-255 range, got %q", fields[0])
+	}
+	if !caaTagRe.MatchString(fields[1]) {
+		return CAAData{}, fmt.Errorf("CAA tag must consist of letters and digits, got %q", fields[1])
+	}
+	value := strings.TrimSpace(fields[2])
+	if len(value) >= 2 && strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`) {
+		value = value[1 : len(value)-1]
+	}
+	return CAAData{
+		Flags: uint8(flags),
+		Tag:   fields[1],
+		Value: value,
+	}, nil
+}
+
+// tag is case-insensitive, value is not
+func (c CAAData) SameKey(c1 CAAData) bool {
+	return strings.EqualFold(c.Tag, c1.Tag) && c.Value == c1.Value
+}
+
 // client API interface
 type DNSApiClient interface {
 	AddRecords(ctx context.Context, domain DNSDomain, records []DNSRecord) error
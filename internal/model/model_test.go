@@ -0,0 +1,101 @@
+package model
+
+import (
+	"testing"
+)
+
+func TestParseCAAData(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		data    DNSRecordData
+		want    CAAData
+		wantErr bool
+	}{
+		{`0 issue "letsencrypt.org"`, CAAData{0, "issue", "letsencrypt.org"}, false},
+		{`128 issuewild ";"`, CAAData{128, "issuewild", ";"}, false},
+		{`0 iodef "mailto:sec@test.com"`, CAAData{0, "iodef", "mailto:sec@test.com"}, false},
+		{`0 issue letsencrypt.org`, CAAData{0, "issue", "letsencrypt.org"}, false},
+		{`0 issue "ca.com; account=12 34"`, CAAData{0, "issue", "ca.com; account=12 34"}, false},
+		{`issue "letsencrypt.org"`, CAAData{}, true},
+		{`256 issue "letsencrypt.org"`, CAAData{}, true},
+		{`0 is-sue "letsencrypt.org"`, CAAData{}, true},
+		{``, CAAData{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseCAAData(tt.data)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseCAAData(%q): want error %v, got %v", tt.data, tt.wantErr, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseCAAData(%q): want %v, got %v", tt.data, tt.want, got)
+		}
+	}
+}
+
+func TestSameKeyCAA(t *testing.T) {
+	t.Parallel()
+	rec := DNSRecord{Type: REC_CAA, Name: "@", Data: `0 issue "letsencrypt.org"`}
+	tests := []struct {
+		data DNSRecordData
+		want bool
+	}{
+		{`0 issue "letsencrypt.org"`, true},
+		{`128 issue "letsencrypt.org"`, true},
+		{`0 ISSUE letsencrypt.org`, true},
+		{`0 issuewild "letsencrypt.org"`, false},
+		{`0 issue "pki.goog"`, false},
+	}
+	for _, tt := range tests {
+		other := rec
+		other.Data = tt.data
+		if got := rec.SameKey(other); got != tt.want {
+			t.Errorf("SameKey(%q, %q): want %v, got %v", rec.Data, tt.data, tt.want, got)
+		}
+	}
+}
+
+func TestSameKeyNormalized(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		rec1, rec2 DNSRecord
+		want       bool
+	}{
+		{
+			DNSRecord{Type: REC_AAAA, Name: "www", Data: "2001:DB8:0:0::1"},
+			DNSRecord{Type: REC_AAAA, Name: "www", Data: "2001:db8::1"},
+			true,
+		},
+		{
+			DNSRecord{Type: REC_MX, Name: "@", Data: "MX1.Test.com."},
+			DNSRecord{Type: REC_MX, Name: "@", Data: "mx1.test.com"},
+			true,
+		},
+		{
+			DNSRecord{Type: REC_SRV, Name: "_sip", Data: "sip1.test.com.", Service: "_sip", Protocol: "_tcp", Port: 5060},
+			DNSRecord{Type: REC_SRV, Name: "_sip", Data: "sip1.test.com", Service: "_sip", Protocol: "_tcp", Port: 5060},
+			true,
+		},
+		{
+			DNSRecord{Type: REC_TXT, Name: "@", Data: `"v=spf1 " "-all"`},
+			DNSRecord{Type: REC_TXT, Name: "@", Data: "v=spf1 -all"},
+			true,
+		},
+		// TXT is case-sensitive
+		{
+			DNSRecord{Type: REC_TXT, Name: "@", Data: "Text"},
+			DNSRecord{Type: REC_TXT, Name: "@", Data: "text"},
+			false,
+		},
+		{
+			DNSRecord{Type: REC_A, Name: "www", Data: "1.1.1.1"},
+			DNSRecord{Type: REC_A, Name: "www", Data: "1.1.1.2"},
+			false,
+		},
+	}
+	for _, tt := range tests {
+		if got := tt.rec1.SameKey(tt.rec2); got != tt.want {
+			t.Errorf("SameKey(%q, %q): want %v, got %v", tt.rec1.Data, tt.rec2.Data, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package model
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+// canonical form of record data, so that equivalent representations
+// (e.g. returned by API for the configured value) compare equal
+//   - A, AAAA: address in standard form (IPv6 compressed and lowercase)
+//   - CNAME, MX, NS, SRV: host name in lowercase, without trailing dot
+//   - TXT: quoted strings are unquoted and joined (`"a" "b"` is `ab`)
+//   - CAA: `<flags> <tag> "<value>"` with lowercase tag
+//
+// data that does not parse is returned as is
+func NormalizeData(t DNSRecordType, d DNSRecordData) DNSRecordData {
+	switch t {
+	case REC_A, REC_AAAA:
+		if addr, err := netip.ParseAddr(string(d)); err == nil {
+			return DNSRecordData(addr.String())
+		}
+	case REC_CNAME, REC_MX, REC_NS, REC_SRV:
+		if d != "." {
+			return DNSRecordData(strings.ToLower(strings.TrimSuffix(string(d), ".")))
+		}
+	case REC_TXT:
+		if strings.HasPrefix(string(d), `"`) {
+			if chunks, err := splitTXTChunks(string(d)); err == nil {
+				return DNSRecordData(strings.Join(chunks, ""))
+			}
+		}
+	case REC_CAA:
+		if caa, err := ParseCAAData(d); err == nil {
+			return DNSRecordData(fmt.Sprintf("%d %s %q", caa.Flags, strings.ToLower(caa.Tag), caa.Value))
+		}
+	}
+	return d
+}
+
+// true if data values are the same after normalization
+func EquivalentData(t DNSRecordType, d1, d2 DNSRecordData) bool {
+	return d1 == d2 || NormalizeData(t, d1) == NormalizeData(t, d2)
+}
@@ -0,0 +1,33 @@
+package model
+
+import "testing"
+
+func TestNormalizeData(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		rType DNSRecordType
+		data  DNSRecordData
+		want  DNSRecordData
+	}{
+		{REC_A, "1.2.3.4", "1.2.3.4"},
+		{REC_A, "Parked", "Parked"},
+		{REC_AAAA, "2001:DB8:0000:0::1", "2001:db8::1"},
+		{REC_AAAA, "::FFFF:1.2.3.4", "::ffff:1.2.3.4"},
+		{REC_CNAME, "WWW.Test.com.", "www.test.com"},
+		{REC_CNAME, "@", "@"},
+		{REC_MX, ".", "."},
+		{REC_NS, "ns1.test.com.", "ns1.test.com"},
+		{REC_SRV, "Sip1.test.com", "sip1.test.com"},
+		{REC_TXT, "Some Text.", "Some Text."},
+		{REC_TXT, `"quoted"`, "quoted"},
+		{REC_TXT, `"part 1" "part 2"`, "part 1part 2"},
+		{REC_TXT, `"unterminated`, `"unterminated`},
+		{REC_CAA, `0 ISSUE letsencrypt.org`, `0 issue "letsencrypt.org"`},
+		{REC_CAA, `bad caa`, `bad caa`},
+	}
+	for _, tt := range tests {
+		if got := NormalizeData(tt.rType, tt.data); got != tt.want {
+			t.Errorf("NormalizeData(%s, %q): want %q, got %q", tt.rType, tt.data, tt.want, got)
+		}
+	}
+}
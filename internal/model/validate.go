@@ -0,0 +1,129 @@
+package model
+
+import (
+	"fmt"
+	"net/netip"
+	"regexp"
+	"strings"
+)
+
+// max length of one TXT character-string, RFC 1035
+const TXT_CHUNK_MAX = 255
+
+// host name label: letters, digits, hyphens inside; underscore is allowed
+// for service names like `_domainkey`
+var hostLabelRe = regexp.MustCompile(`^[a-zA-Z0-9_]([a-zA-Z0-9_-]{0,61}[a-zA-Z0-9_])?$`)
+
+// check that data is valid for the record type
+//   - A: IPv4 address (or "Parked" placeholder used by GoDaddy)
+//   - AAAA: IPv6 address
+//   - CNAME, MX, NS, SRV: host name (not an IP); "@" for the domain itself,
+//     "." for null MX or SRV ("no service")
+//   - TXT: one string up to 255 bytes, or longer one split into quoted
+//     strings like `"part 1" "part 2"`, each up to 255 bytes
+//   - CAA: `<flags> <tag> "<value>"`
+//
+// other types are not checked
+func ValidateData(t DNSRecordType, d DNSRecordData) error {
+	switch t {
+	case REC_A:
+		if d == "Parked" {
+			return nil
+		}
+		if addr, err := netip.ParseAddr(string(d)); err != nil || !addr.Is4() {
+			return fmt.Errorf("A record data must be an IPv4 address, got %q", d)
+		}
+	case REC_AAAA:
+		if addr, err := netip.ParseAddr(string(d)); err != nil || !addr.Is6() {
+			return fmt.Errorf("AAAA record data must be an IPv6 address, got %q", d)
+		}
+	case REC_CNAME, REC_MX, REC_NS, REC_SRV:
+		if d == "@" || d == "." && (t == REC_MX || t == REC_SRV) {
+			return nil
+		}
+		if err := ValidateHostName(string(d)); err != nil {
+			return fmt.Errorf("%s record data must be a host name: %w", t, err)
+		}
+	case REC_TXT:
+		return validateTXTData(string(d))
+	case REC_CAA:
+		_, err := ParseCAAData(d)
+		return err
+	}
+	return nil
+}
+
+// check host name syntax, RFC 1123, with optional trailing dot
+func ValidateHostName(name string) error {
+	if _, err := netip.ParseAddr(name); err == nil {
+		return fmt.Errorf("got IP address %q", name)
+	}
+	fqdn := strings.TrimSuffix(name, ".")
+	if fqdn == "" || len(fqdn) > 253 {
+		return fmt.Errorf("length must be 1 to 253 characters, got %q", name)
+	}
+	for _, label := range strings.Split(fqdn, ".") {
+		if !hostLabelRe.MatchString(label) {
+			return fmt.Errorf("invalid label %q in %q: must be 1 to 63 letters, digits "+
+				"or hyphens, not starting or ending with hyphen", label, name)
+		}
+	}
+	return nil
+}
+
+// unquoted TXT data must fit into one string, quoted one is checked by chunks
+func validateTXTData(data string) error {
+	if !strings.HasPrefix(data, `"`) {
+		if len(data) > TXT_CHUNK_MAX {
+			return fmt.Errorf("TXT record data is %d bytes long, which exceeds the limit of %d "+
+				"for one string: split it into quoted strings like `\"part 1\" \"part 2\"`",
+				len(data), TXT_CHUNK_MAX)
+		}
+		return nil
+	}
+	chunks, err := splitTXTChunks(data)
+	if err != nil {
+		return err
+	}
+	for i, chunk := range chunks {
+		if len(chunk) > TXT_CHUNK_MAX {
+			return fmt.Errorf("TXT record string %d is %d bytes long, which exceeds the limit of %d",
+				i+1, len(chunk), TXT_CHUNK_MAX)
+		}
+	}
+	return nil
+}
+
+// split `"part 1" "part 2"` into unquoted parts; `\"` and `\\` are escapes
+func splitTXTChunks(data string) ([]string, error) {
+	chunks := []string{}
+	rest := strings.TrimSpace(data)
+	for rest != "" {
+		if rest[0] != '"' {
+			return nil, fmt.Errorf("TXT record data must be a list of quoted strings, "+
+				"got unquoted text at %q", rest)
+		}
+		var chunk strings.Builder
+		closed := false
+		i := 1
+		for ; i < len(rest); i++ {
+			c := rest[i]
+			if c == '\\' && i+1 < len(rest) {
+				i++
+				chunk.WriteByte(rest[i])
+				continue
+			}
+			if c == '"' {
+				closed = true
+				break
+			}
+			chunk.WriteByte(c)
+		}
+		if !closed {
+			return nil, fmt.Errorf("TXT record data has unterminated quoted string %q", rest)
+		}
+		chunks = append(chunks, chunk.String())
+		rest = strings.TrimLeft(rest[i+1:], " \t")
+	}
+	return chunks, nil
+}
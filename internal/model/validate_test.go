@@ -0,0 +1,61 @@
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateData(t *testing.T) {
+	t.Parallel()
+	long := strings.Repeat("x", TXT_CHUNK_MAX)
+	tests := []struct {
+		rType   DNSRecordType
+		data    DNSRecordData
+		wantErr bool
+	}{
+		{REC_A, "1.2.3.4", false},
+		{REC_A, "Parked", false},
+		{REC_A, "1.2.3", true},
+		{REC_A, "2001:db8::1", true},
+		{REC_A, "www.test.com", true},
+		{REC_AAAA, "2001:db8::1", false},
+		{REC_AAAA, "::ffff:1.2.3.4", false},
+		{REC_AAAA, "1.2.3.4", true},
+		{REC_AAAA, "2001:db8::g", true},
+		{REC_CNAME, "www.test.com", false},
+		{REC_CNAME, "www.test.com.", false},
+		{REC_CNAME, "s1._domainkey.mail.com", false},
+		{REC_CNAME, "@", false},
+		{REC_CNAME, "1.2.3.4", true},
+		{REC_CNAME, "-bad.test.com", true},
+		{REC_CNAME, "bad..test.com", true},
+		{REC_CNAME, "bad name.test.com", true},
+		{REC_CNAME, DNSRecordData(strings.Repeat("a", 64) + ".test.com"), true},
+		{REC_CNAME, ".", true},
+		{REC_MX, "mx1.test.com", false},
+		{REC_MX, ".", false},
+		{REC_MX, "2001:db8::1", true},
+		{REC_NS, "ns1.test.com", false},
+		{REC_NS, "", true},
+		{REC_SRV, "sip1.test.com", false},
+		{REC_SRV, "1.2.3.4", true},
+		{REC_TXT, "test text", false},
+		{REC_TXT, "updated text: with separator", false},
+		{REC_TXT, DNSRecordData(long), false},
+		{REC_TXT, DNSRecordData(long + "x"), true},
+		{REC_TXT, DNSRecordData(`"` + long + `" "` + long + `"`), false},
+		{REC_TXT, DNSRecordData(`"` + long + `x" "short"`), true},
+		{REC_TXT, `"with \" quote" "second"`, false},
+		{REC_TXT, `"unterminated`, true},
+		{REC_TXT, `"quoted" unquoted`, true},
+		{REC_CAA, `0 issue "letsencrypt.org"`, false},
+		{REC_CAA, `issue "letsencrypt.org"`, true},
+		{REC_SOA, "anything", false},
+	}
+	for _, tt := range tests {
+		err := ValidateData(tt.rType, tt.data)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ValidateData(%s, %q): want error %v, got %v", tt.rType, tt.data, tt.wantErr, err)
+		}
+	}
+}
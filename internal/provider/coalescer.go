@@ -0,0 +1,209 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/pkg/errors"
+	"github.com/veksh/terraform-provider-godaddy-dns/internal/client"
+	"github.com/veksh/terraform-provider-godaddy-dns/internal/model"
+	"github.com/veksh/terraform-provider-godaddy-dns/libs/ratelimiter"
+)
+
+// change of one value of multi-valued record: old value (nil for create)
+// is replaced with new one (nil for delete)
+type recordChange struct {
+	Old *model.DNSRecord
+	New *model.DNSRecord
+}
+
+func (c recordChange) key(rDomain model.DNSDomain) lockKey {
+	rec := c.New
+	if rec == nil {
+		rec = c.Old
+	}
+	return lockKey{Domain: rDomain, Type: rec.Type, Name: rec.Name}
+}
+
+// write coalescer: changes for the same domain + type + name submitted within
+// a short window are applied together
+//   - creates only: one PATCH with all the new records
+//   - otherwise: one GET and one PUT (or DELETE if nothing is left)
+//   - if batch fails, changes are re-applied one by one, so every caller
+//     gets its own result (e.g. only duplicate record fails)
+//   - batch is applied under record lock, so callers must not hold it
+//   - changes for different shopper IDs go to separate batches
+type writeCoalescer struct {
+	client model.DNSApiClient
+	locker *recordLocker
+	window time.Duration
+	// time source for batch window; real time if nil (could be set for tests)
+	clock ratelimiter.Clock
+	// test hooks: change is added to pending batch, batch of n changes is
+	// flushed (0 if all of them were cancelled)
+	enqueued func()
+	flushed  func(n int)
+	mu       sync.Mutex
+	pending  map[batchKey]*changeBatch
+}
+
+// record key + shopper ID from context: batch is applied on behalf of one
+// shopper
+type batchKey struct {
+	lockKey
+	ShopperID string
+}
+
+type changeBatch struct {
+	// first submitter context (without cancellation) for logging and shopper id
+	ctx     context.Context
+	changes []recordChange
+	results []chan error
+}
+
+func newWriteCoalescer(client model.DNSApiClient, locker *recordLocker, window time.Duration) *writeCoalescer {
+	return &writeCoalescer{
+		client:  client,
+		locker:  locker,
+		window:  window,
+		pending: map[batchKey]*changeBatch{},
+	}
+}
+
+// submit change and wait for the batch with it to be applied; on cancel,
+// change is dropped if batch is still pending, otherwise batch result is
+// waited for anyway (it is applied without cancellation)
+func (wc *writeCoalescer) Submit(ctx context.Context, rDomain model.DNSDomain, change recordChange) error {
+	key := batchKey{lockKey: change.key(rDomain), ShopperID: client.ShopperIDFromContext(ctx)}
+	result := make(chan error, 1)
+
+	wc.mu.Lock()
+	batch, ok := wc.pending[key]
+	if !ok {
+		batch = &changeBatch{ctx: context.WithoutCancel(ctx)}
+		wc.pending[key] = batch
+		wc.afterWindow(func() { wc.flush(key) })
+	}
+	batch.changes = append(batch.changes, change)
+	batch.results = append(batch.results, result)
+	wc.mu.Unlock()
+	if wc.enqueued != nil {
+		wc.enqueued()
+	}
+
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+	}
+
+	wc.mu.Lock()
+	if wc.pending[key] == batch {
+		i := slices.Index(batch.results, result)
+		batch.changes = slices.Delete(batch.changes, i, i+1)
+		batch.results = slices.Delete(batch.results, i, i+1)
+		wc.mu.Unlock()
+		return ctx.Err()
+	}
+	wc.mu.Unlock()
+	// already being applied
+	return <-result
+}
+
+// run f when batch window is over
+func (wc *writeCoalescer) afterWindow(f func()) {
+	if wc.clock == nil {
+		time.AfterFunc(wc.window, f)
+		return
+	}
+	timer := wc.clock.NewTimer(wc.window)
+	go func() {
+		<-timer.C()
+		f()
+	}()
+}
+
+func (wc *writeCoalescer) flush(key batchKey) {
+	wc.mu.Lock()
+	batch := wc.pending[key]
+	delete(wc.pending, key)
+	wc.mu.Unlock()
+	if wc.flushed != nil {
+		defer wc.flushed(len(batch.changes))
+	}
+	if len(batch.changes) == 0 {
+		// all the submitters are gone
+		return
+	}
+
+	unlock := wc.locker.Lock(key.Domain, key.Type, key.Name)
+	defer unlock()
+
+	ctx := tflog.SetField(batch.ctx, "batch_size", len(batch.changes))
+	tflog.Info(ctx, "batch: start")
+	defer tflog.Info(ctx, "batch: end")
+
+	err := wc.apply(ctx, key.lockKey, batch.changes)
+	if err == nil || len(batch.changes) == 1 {
+		for _, res := range batch.results {
+			res <- err
+		}
+		return
+	}
+	tflog.Warn(ctx, fmt.Sprintf("batch failed (%s), applying changes one by one", err))
+	for i, change := range batch.changes {
+		batch.results[i] <- wc.apply(ctx, key.lockKey, []recordChange{change})
+	}
+}
+
+// apply changes for records with the same type + name
+func (wc *writeCoalescer) apply(ctx context.Context, key lockKey, changes []recordChange) error {
+	newRecs := []model.DNSRecord{}
+	createOnly := true
+	for _, change := range changes {
+		if change.Old != nil {
+			createOnly = false
+		}
+		if change.New != nil {
+			newRecs = append(newRecs, *change.New)
+		}
+	}
+	if createOnly {
+		return wc.client.AddRecords(ctx, key.Domain, newRecs)
+	}
+
+	apiAllRecs, err := wc.client.GetRecords(ctx, key.Domain, key.Type, key.Name)
+	if err != nil {
+		return errors.Wrap(err, "getting DNS records to keep failed")
+	}
+	current := make([]model.DNSUpdateRecord, 0, len(apiAllRecs))
+	keep := []model.DNSUpdateRecord{}
+	for _, rec := range apiAllRecs {
+		current = append(current, rec.ToUpdate())
+		replaced := slices.ContainsFunc(changes, func(c recordChange) bool {
+			return c.Old != nil && rec.SameKey(*c.Old)
+		})
+		if !replaced {
+			keep = append(keep, rec.ToUpdate())
+		}
+	}
+	for _, rec := range newRecs {
+		if updRec := rec.ToUpdate(); slices.Index(keep, updRec) < 0 {
+			keep = append(keep, updRec)
+		}
+	}
+
+	switch {
+	case sameUpdateRecs(key.Type, current, keep):
+		tflog.Info(ctx, "batch: nothing left to do")
+		return nil
+	case len(keep) == 0:
+		return wc.client.DelRecords(ctx, key.Domain, key.Type, key.Name)
+	default:
+		return wc.client.SetRecords(ctx, key.Domain, key.Type, key.Name, keep)
+	}
+}
@@ -0,0 +1,259 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/mock"
+	"github.com/veksh/terraform-provider-godaddy-dns/internal/client"
+	"github.com/veksh/terraform-provider-godaddy-dns/internal/model"
+	"github.com/veksh/terraform-provider-godaddy-dns/libs/ratelimiter"
+)
+
+// records in any order
+func recsInAnyOrder(want []model.DNSRecord) interface{} {
+	return mock.MatchedBy(func(got []model.DNSRecord) bool {
+		if len(got) != len(want) {
+			return false
+		}
+		for _, w := range want {
+			if !slices.Contains(got, w) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+func txtRec(data string) model.DNSRecord {
+	return model.DNSRecord{
+		Type: model.REC_TXT,
+		Name: "_test",
+		Data: model.DNSRecordData(data),
+		TTL:  3600,
+	}
+}
+
+// submit all the changes concurrently, return results in the same order
+func submitAll(wc *writeCoalescer, changes []recordChange) []error {
+	res := make([]error, len(changes))
+	var wg sync.WaitGroup
+	for i, c := range changes {
+		wg.Add(1)
+		go func(i int, c recordChange) {
+			defer wg.Done()
+			res[i] = wc.Submit(context.Background(), TEST_DOMAIN, c)
+		}(i, c)
+	}
+	wg.Wait()
+	return res
+}
+
+func TestCoalescer_CreatesInOnePatch(t *testing.T) {
+	t.Parallel()
+	recs := []model.DNSRecord{}
+	changes := []recordChange{}
+	for i := 0; i < 5; i++ {
+		rec := txtRec(fmt.Sprintf("value %d", i))
+		recs = append(recs, rec)
+		changes = append(changes, recordChange{New: &rec})
+	}
+	mClient := model.NewMockDNSApiClient(t)
+	mClient.EXPECT().AddRecords(mock.Anything, model.DNSDomain(TEST_DOMAIN), recsInAnyOrder(recs)).Return(nil).Once()
+
+	wc := newWriteCoalescer(mClient, newRecordLocker(), 100*time.Millisecond)
+	for i, err := range submitAll(wc, changes) {
+		if err != nil {
+			t.Errorf("change %d failed: %s", i, err)
+		}
+	}
+}
+
+func TestCoalescer_UpdatesInOnePut(t *testing.T) {
+	t.Parallel()
+	other, old1, old2, gone := txtRec("other"), txtRec("old 1"), txtRec("old 2"), txtRec("gone")
+	new1, new2, added := txtRec("new 1"), txtRec("new 2"), txtRec("added")
+
+	mClient := model.NewMockDNSApiClient(t)
+	mClient.EXPECT().GetRecords(mock.Anything, model.DNSDomain(TEST_DOMAIN), model.REC_TXT, model.DNSRecordName("_test")).
+		Return([]model.DNSRecord{other, old1, old2}, nil).Once()
+	mClient.EXPECT().SetRecords(mock.Anything, model.DNSDomain(TEST_DOMAIN), model.REC_TXT, model.DNSRecordName("_test"),
+		updRecsInAnyOrder([]model.DNSUpdateRecord{other.ToUpdate(), new1.ToUpdate(), new2.ToUpdate(), added.ToUpdate()})).
+		Return(nil).Once()
+
+	wc := newWriteCoalescer(mClient, newRecordLocker(), 100*time.Millisecond)
+	for i, err := range submitAll(wc, []recordChange{
+		{Old: &old1, New: &new1},
+		{Old: &old2, New: &new2},
+		{New: &added},
+		{Old: &gone},
+	}) {
+		if err != nil {
+			t.Errorf("change %d failed: %s", i, err)
+		}
+	}
+}
+
+func TestCoalescer_DeleteLastValues(t *testing.T) {
+	t.Parallel()
+	old1, old2 := txtRec("old 1"), txtRec("old 2")
+
+	mClient := model.NewMockDNSApiClient(t)
+	mClient.EXPECT().GetRecords(mock.Anything, model.DNSDomain(TEST_DOMAIN), model.REC_TXT, model.DNSRecordName("_test")).
+		Return([]model.DNSRecord{old1, old2}, nil).Once()
+	mClient.EXPECT().DelRecords(mock.Anything, model.DNSDomain(TEST_DOMAIN), model.REC_TXT, model.DNSRecordName("_test")).
+		Return(nil).Once()
+
+	wc := newWriteCoalescer(mClient, newRecordLocker(), 100*time.Millisecond)
+	for i, err := range submitAll(wc, []recordChange{{Old: &old1}, {Old: &old2}}) {
+		if err != nil {
+			t.Errorf("change %d failed: %s", i, err)
+		}
+	}
+}
+
+func TestCoalescer_PerChangeResultsOnFailure(t *testing.T) {
+	t.Parallel()
+	good1, good2, dup := txtRec("good 1"), txtRec("good 2"), txtRec("duplicate")
+	errDup := errors.New("duplicate record")
+
+	mClient := model.NewMockDNSApiClient(t)
+	mClient.EXPECT().AddRecords(mock.Anything, model.DNSDomain(TEST_DOMAIN), recsInAnyOrder([]model.DNSRecord{good1, good2, dup})).
+		Return(errDup).Once()
+	mClient.EXPECT().AddRecords(mock.Anything, model.DNSDomain(TEST_DOMAIN), []model.DNSRecord{good1}).Return(nil).Once()
+	mClient.EXPECT().AddRecords(mock.Anything, model.DNSDomain(TEST_DOMAIN), []model.DNSRecord{good2}).Return(nil).Once()
+	mClient.EXPECT().AddRecords(mock.Anything, model.DNSDomain(TEST_DOMAIN), []model.DNSRecord{dup}).Return(errDup).Once()
+
+	wc := newWriteCoalescer(mClient, newRecordLocker(), 100*time.Millisecond)
+	res := submitAll(wc, []recordChange{{New: &good1}, {New: &dup}, {New: &good2}})
+	if res[0] != nil || res[2] != nil {
+		t.Errorf("good changes failed: %v", res)
+	}
+	if res[1] != errDup {
+		t.Errorf("want duplicate error for bad change, got %v", res[1])
+	}
+}
+
+func TestCoalescer_WaitsForRecordLock(t *testing.T) {
+	t.Parallel()
+	rec := txtRec("value")
+	mClient := model.NewMockDNSApiClient(t)
+	mClient.EXPECT().AddRecords(mock.Anything, model.DNSDomain(TEST_DOMAIN), []model.DNSRecord{rec}).Return(nil).Once()
+
+	locker := newRecordLocker()
+	wc := newWriteCoalescer(mClient, locker, 10*time.Millisecond)
+	unlock := locker.Lock(TEST_DOMAIN, model.REC_TXT, "_test")
+	done := make(chan error)
+	go func() {
+		done <- wc.Submit(context.Background(), TEST_DOMAIN, recordChange{New: &rec})
+	}()
+	select {
+	case <-done:
+		t.Fatal("batch applied while record is locked")
+	case <-time.After(lockWait):
+	}
+	unlock()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCoalescer_SeparateBatchesPerShopper(t *testing.T) {
+	t.Parallel()
+	own, sub := txtRec("own"), txtRec("sub-account")
+	shopper := func(id string) interface{} {
+		return mock.MatchedBy(func(ctx context.Context) bool {
+			return client.ShopperIDFromContext(ctx) == id
+		})
+	}
+	mClient := model.NewMockDNSApiClient(t)
+	mClient.EXPECT().AddRecords(shopper(""), model.DNSDomain(TEST_DOMAIN), []model.DNSRecord{own}).Return(nil).Once()
+	mClient.EXPECT().AddRecords(shopper("12345"), model.DNSDomain(TEST_DOMAIN), []model.DNSRecord{sub}).Return(nil).Once()
+
+	wc := newWriteCoalescer(mClient, newRecordLocker(), 50*time.Millisecond)
+	done := make(chan error, 2)
+	go func() {
+		done <- wc.Submit(context.Background(), TEST_DOMAIN, recordChange{New: &own})
+	}()
+	go func() {
+		ctx := client.ContextWithShopperID(context.Background(), "12345")
+		done <- wc.Submit(ctx, TEST_DOMAIN, recordChange{New: &sub})
+	}()
+	for i := 0; i < 2; i++ {
+		if err := <-done; err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+// coalescer with fake clock for batch window; returns channels signalled
+// on every enqueued change and with size of every flushed batch
+func fakeClockCoalescer(c model.DNSApiClient) (*writeCoalescer, *ratelimiter.FakeClock, chan struct{}, chan int) {
+	clock := ratelimiter.NewFakeClock(time.Now())
+	enqueued, flushed := make(chan struct{}, 10), make(chan int, 10)
+	wc := newWriteCoalescer(c, newRecordLocker(), 100*time.Millisecond)
+	wc.clock = clock
+	wc.enqueued = func() { enqueued <- struct{}{} }
+	wc.flushed = func(n int) { flushed <- n }
+	return wc, clock, enqueued, flushed
+}
+
+func TestCoalescer_CancelledChangeDropped(t *testing.T) {
+	t.Parallel()
+	kept, cancelled := txtRec("kept"), txtRec("cancelled")
+	mClient := model.NewMockDNSApiClient(t)
+	mClient.EXPECT().AddRecords(mock.Anything, model.DNSDomain(TEST_DOMAIN), []model.DNSRecord{kept}).Return(nil).Once()
+
+	wc, clock, enqueued, flushed := fakeClockCoalescer(mClient)
+	ctx, cancel := context.WithCancel(context.Background())
+	keptDone, cancelledDone := make(chan error, 1), make(chan error, 1)
+	go func() {
+		keptDone <- wc.Submit(context.Background(), TEST_DOMAIN, recordChange{New: &kept})
+	}()
+	go func() {
+		cancelledDone <- wc.Submit(ctx, TEST_DOMAIN, recordChange{New: &cancelled})
+	}()
+	<-enqueued
+	<-enqueued
+	// batch window is not over until clock is advanced
+	cancel()
+	if err := <-cancelledDone; err != context.Canceled {
+		t.Errorf("want cancelled change to return context.Canceled, got %v", err)
+	}
+	clock.Advance(100 * time.Millisecond)
+	if err := <-keptDone; err != nil {
+		t.Errorf("want kept change to succeed, got %v", err)
+	}
+	if n := <-flushed; n != 1 {
+		t.Errorf("want batch of 1 change, got %d", n)
+	}
+}
+
+func TestCoalescer_AllCancelled(t *testing.T) {
+	t.Parallel()
+	rec := txtRec("cancelled")
+	// no calls expected
+	mClient := model.NewMockDNSApiClient(t)
+
+	wc, clock, enqueued, flushed := fakeClockCoalescer(mClient)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- wc.Submit(ctx, TEST_DOMAIN, recordChange{New: &rec})
+	}()
+	<-enqueued
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("want context.Canceled, got %v", err)
+	}
+	// empty batch is flushed without API calls
+	clock.Advance(100 * time.Millisecond)
+	if n := <-flushed; n != 0 {
+		t.Errorf("want empty batch, got %d changes", n)
+	}
+}
@@ -0,0 +1,98 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/veksh/terraform-provider-godaddy-dns/internal/model"
+)
+
+var (
+	_ basetypes.StringTypable                    = recordDataType{}
+	_ basetypes.StringValuableWithSemanticEquals = recordDataValue{}
+)
+
+// string type for record data with semantic equality: record type is not
+// known at this level, so only IP addresses are compared by value (they are
+// unambiguous, e.g. `2001:DB8::1` vs `2001:db8::1`); the rest of per-type
+// normalization is done in Read (see keepStateData)
+type recordDataType struct {
+	basetypes.StringType
+}
+
+func (t recordDataType) Equal(o attr.Type) bool {
+	other, ok := o.(recordDataType)
+	return ok && t.StringType.Equal(other.StringType)
+}
+
+func (t recordDataType) String() string {
+	return "recordDataType"
+}
+
+func (t recordDataType) ValueFromString(ctx context.Context, in basetypes.StringValue) (basetypes.StringValuable, diag.Diagnostics) {
+	return recordDataValue{StringValue: in}, nil
+}
+
+func (t recordDataType) ValueFromTerraform(ctx context.Context, in tftypes.Value) (attr.Value, error) {
+	attrValue, err := t.StringType.ValueFromTerraform(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	stringValue, ok := attrValue.(basetypes.StringValue)
+	if !ok {
+		return nil, fmt.Errorf("unexpected value type of %T", attrValue)
+	}
+	return recordDataValue{StringValue: stringValue}, nil
+}
+
+func (t recordDataType) ValueType(ctx context.Context) attr.Value {
+	return recordDataValue{}
+}
+
+type recordDataValue struct {
+	basetypes.StringValue
+}
+
+func newRecordData(d model.DNSRecordData) recordDataValue {
+	return recordDataValue{StringValue: basetypes.NewStringValue(string(d))}
+}
+
+func (v recordDataValue) Equal(o attr.Value) bool {
+	other, ok := o.(recordDataValue)
+	return ok && v.StringValue.Equal(other.StringValue)
+}
+
+func (v recordDataValue) Type(ctx context.Context) attr.Type {
+	return recordDataType{}
+}
+
+func (v recordDataValue) StringSemanticEquals(ctx context.Context, newValuable basetypes.StringValuable) (bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	newValue, ok := newValuable.(recordDataValue)
+	if !ok {
+		diags.AddError("Semantic Equality Check Error",
+			fmt.Sprintf("Expected value type %T, got %T", v, newValuable))
+		return false, diags
+	}
+	addr, err := netip.ParseAddr(v.ValueString())
+	newAddr, newErr := netip.ParseAddr(newValue.ValueString())
+	return err == nil && newErr == nil && addr == newAddr, diags
+}
+
+// keep data from state (i.e. as configured) if API returns equivalent one,
+// like lowercase host name or compressed IPv6 address for the same record
+func keepStateData(rec model.DNSRecord, stateRecs []model.DNSRecord) model.DNSRecord {
+	for _, stateRec := range stateRecs {
+		if stateRec.Type == rec.Type && stateRec.Name == rec.Name &&
+			model.EquivalentData(rec.Type, rec.Data, stateRec.Data) {
+			rec.Data = stateRec.Data
+			break
+		}
+	}
+	return rec
+}
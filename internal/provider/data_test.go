@@ -0,0 +1,58 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/veksh/terraform-provider-godaddy-dns/internal/model"
+)
+
+func TestRecordDataSemanticEquals(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		old, new model.DNSRecordData
+		want     bool
+	}{
+		{"2001:DB8:0::1", "2001:db8::1", true},
+		{"1.2.3.4", "1.2.3.4", true},
+		{"1.2.3.4", "1.2.3.5", false},
+		// could be TXT: case matters
+		{"WWW.test.com", "www.test.com", false},
+	}
+	for _, tt := range tests {
+		got, diags := newRecordData(tt.old).StringSemanticEquals(context.Background(), newRecordData(tt.new))
+		if diags.HasError() {
+			t.Fatal(diags)
+		}
+		if got != tt.want {
+			t.Errorf("%q vs %q: want %v, got %v", tt.old, tt.new, tt.want, got)
+		}
+	}
+}
+
+func TestKeepStateData(t *testing.T) {
+	t.Parallel()
+	state := []model.DNSRecord{
+		{Type: model.REC_MX, Name: "@", Data: "MX1.test.com."},
+		{Type: model.REC_TXT, Name: "@", Data: `"v=spf1 " "-all"`},
+	}
+	tests := []struct {
+		rec  model.DNSRecord
+		want model.DNSRecordData
+	}{
+		{model.DNSRecord{Type: model.REC_MX, Name: "@", Data: "mx1.test.com"}, "MX1.test.com."},
+		{model.DNSRecord{Type: model.REC_TXT, Name: "@", Data: "v=spf1 -all"}, `"v=spf1 " "-all"`},
+		// other value or name: as returned by API
+		{model.DNSRecord{Type: model.REC_MX, Name: "@", Data: "mx2.test.com"}, "mx2.test.com"},
+		{model.DNSRecord{Type: model.REC_MX, Name: "sub", Data: "mx1.test.com"}, "mx1.test.com"},
+	}
+	for _, tt := range tests {
+		got := keepStateData(tt.rec, state)
+		want := tt.rec
+		want.Data = tt.want
+		if !cmp.Equal(want, got) {
+			t.Error(cmp.Diff(want, got))
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package provider
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/pkg/errors"
+	"github.com/veksh/terraform-provider-godaddy-dns/internal/client"
+)
+
+// record attributes API validation errors could be attributed to
+var recordErrorAttrs = []string{"data", "ttl", "priority", "service", "protocol", "port", "weight"}
+
+// add diagnostics for client error: for API errors, summary is set by
+// error code, and per-field errors are attached to attributes (if the
+// resource has them, i.e. field name is one of attrs)
+func addClientError(diags *diag.Diagnostics, msg string, err error, attrs ...string) {
+	var apiErr *client.APIError
+	if !errors.As(err, &apiErr) {
+		diags.AddError("Client Error", fmt.Sprintf("%s: %s", msg, err))
+		return
+	}
+
+	detail := fmt.Sprintf("%s: %s", msg, apiErr.Message)
+	if apiErr.Message == "" {
+		detail = fmt.Sprintf("%s: %s", msg, apiErr)
+	}
+	if apiErr.RequestID != "" {
+		detail += fmt.Sprintf(" (request id %s)", apiErr.RequestID)
+	}
+
+	switch {
+	case apiErr.Code == client.ERR_UNABLE_TO_AUTH || apiErr.StatusCode == http.StatusUnauthorized:
+		diags.AddError("Authentication Failed", detail+
+			"\nCheck api_key and api_secret (or GODADDY_API_KEY and GODADDY_API_SECRET)")
+	case apiErr.Code == client.ERR_ACCESS_DENIED || apiErr.StatusCode == http.StatusForbidden:
+		diags.AddError("Access Denied", detail+
+			"\nCheck that domain belongs to account and that API key has access to it")
+	case apiErr.Code == client.ERR_NOT_FOUND || apiErr.StatusCode == http.StatusNotFound:
+		diags.AddError("Not Found", detail+
+			"\nCheck that domain exists and is managed by GoDaddy DNS")
+	case apiErr.Code == client.ERR_DUPLICATE_RECORD:
+		if slices.Contains(attrs, "data") {
+			diags.AddAttributeError(path.Root("data"), "Duplicate Record", detail+
+				"\nRecord already exists: import it or remove the duplicate")
+		} else {
+			diags.AddError("Duplicate Record", detail)
+		}
+	case apiErr.Code == client.ERR_TOO_MANY_REQUESTS || apiErr.StatusCode == http.StatusTooManyRequests:
+		diags.AddError("API Rate Limit Exceeded", detail+
+			"\nConsider increasing max_retries or lowering parallelism")
+	default:
+		attributed := false
+		for _, f := range apiErr.Fields {
+			if slices.Contains(attrs, f.Name()) {
+				diags.AddAttributeError(path.Root(f.Name()), "Invalid Attribute Value",
+					fmt.Sprintf("%s: %s (%s)", msg, f.Message, f.Code))
+				attributed = true
+			}
+		}
+		if !attributed {
+			diags.AddError("Client Error", fmt.Sprintf("%s: %s", msg, apiErr))
+		}
+	}
+}
+
+// API reports that record (or domain) does not exist
+func isNotFound(err error) bool {
+	var apiErr *client.APIError
+	return errors.As(err, &apiErr) &&
+		(apiErr.Code == client.ERR_NOT_FOUND || apiErr.StatusCode == http.StatusNotFound)
+}
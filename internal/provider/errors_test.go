@@ -0,0 +1,95 @@
+package provider
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/pkg/errors"
+	"github.com/veksh/terraform-provider-godaddy-dns/internal/client"
+)
+
+func TestAddClientError(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name        string
+		err         error
+		attrs       []string
+		wantSummary string
+		wantPath    path.Path
+	}{
+		{
+			name:        "plain error",
+			err:         errors.New("connection refused"),
+			wantSummary: "Client Error",
+		},
+		{
+			name:        "auth",
+			err:         &client.APIError{StatusCode: http.StatusUnauthorized, Code: client.ERR_UNABLE_TO_AUTH},
+			wantSummary: "Authentication Failed",
+		},
+		{
+			name:        "access denied by status",
+			err:         &client.APIError{StatusCode: http.StatusForbidden},
+			wantSummary: "Access Denied",
+		},
+		{
+			name:        "not found",
+			err:         errors.Wrap(&client.APIError{StatusCode: http.StatusNotFound, Code: client.ERR_NOT_FOUND}, "wrapped"),
+			wantSummary: "Not Found",
+		},
+		{
+			name:        "duplicate on record",
+			err:         &client.APIError{StatusCode: http.StatusUnprocessableEntity, Code: client.ERR_DUPLICATE_RECORD},
+			attrs:       recordErrorAttrs,
+			wantSummary: "Duplicate Record",
+			wantPath:    path.Root("data"),
+		},
+		{
+			name:        "duplicate on zone",
+			err:         &client.APIError{StatusCode: http.StatusUnprocessableEntity, Code: client.ERR_DUPLICATE_RECORD},
+			wantSummary: "Duplicate Record",
+		},
+		{
+			name: "field error",
+			err: &client.APIError{
+				StatusCode: http.StatusUnprocessableEntity,
+				Code:       client.ERR_INVALID_BODY,
+				Fields:     []client.APIErrorField{{Path: "records[0].ttl", Code: "TOO_SMALL", Message: "too small"}},
+			},
+			attrs:       recordErrorAttrs,
+			wantSummary: "Invalid Attribute Value",
+			wantPath:    path.Root("ttl"),
+		},
+		{
+			name: "field error without attrs",
+			err: &client.APIError{
+				StatusCode: http.StatusUnprocessableEntity,
+				Code:       client.ERR_INVALID_BODY,
+				Fields:     []client.APIErrorField{{Path: "records[0].ttl", Code: "TOO_SMALL", Message: "too small"}},
+			},
+			wantSummary: "Client Error",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var diags diag.Diagnostics
+			addClientError(&diags, "Testing", tt.err, tt.attrs...)
+			if diags.ErrorsCount() != 1 {
+				t.Fatalf("want 1 error, got %d: %v", diags.ErrorsCount(), diags)
+			}
+			d := diags.Errors()[0]
+			if d.Summary() != tt.wantSummary {
+				t.Errorf("want summary %q, got %q", tt.wantSummary, d.Summary())
+			}
+			var gotPath path.Path
+			if wp, ok := d.(diag.DiagnosticWithPath); ok {
+				gotPath = wp.Path()
+			}
+			if !gotPath.Equal(tt.wantPath) {
+				t.Errorf("want path %q, got %q", tt.wantPath, gotPath)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/veksh/terraform-provider-godaddy-dns/internal/model"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// records not exported: managed by GoDaddy, like in zone default ignore;
+// top-level NS delegates the domain itself, NS for sub-domains are exported
+var exportSkip = zoneDefaultIgnore
+
+// GoDaddy parking page placeholder for top-level A
+const PARKED_DATA = model.DNSRecordData("Parked")
+
+// chars not allowed in resource labels (underscores are collapsed too)
+var exportLabelRe = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// ExportDomain writes all the records of domain as HCL `godaddy-dns_record`
+// resources along with Terraform 1.5+ `import {}` blocks for them; records
+// managed by GoDaddy (SOA, top-level NS, parking) and unsupported types are
+// listed in comments
+func ExportDomain(ctx context.Context, apiClient model.DNSApiClient, rDomain model.DNSDomain, w io.Writer) error {
+	apiRecs, err := apiClient.GetRecords(ctx, rDomain, "", "")
+	if err != nil {
+		return fmt.Errorf("cannot get records for %s: %w", rDomain, err)
+	}
+	// import id starts with type and name, so it is good enough as sort key
+	slices.SortFunc(apiRecs, func(a, b model.DNSRecord) int {
+		return strings.Compare(formatImportID(rDomain, a), formatImportID(rDomain, b))
+	})
+
+	f := hclwrite.NewEmptyFile()
+	body := f.Body()
+	exported := []model.DNSRecord{}
+	for _, rec := range apiRecs {
+		if reason := exportSkipReason(rec); reason != "" {
+			body.AppendUnstructuredTokens(hclwrite.Tokens{{
+				Type:  hclsyntax.TokenComment,
+				Bytes: []byte(fmt.Sprintf("# skipped (%s): %s\n", reason, formatImportID(rDomain, rec))),
+			}})
+			continue
+		}
+		exported = append(exported, rec)
+	}
+
+	labels := map[string]bool{}
+	for i, rec := range exported {
+		label := exportLabel(rec, labels)
+		if i > 0 || len(exported) < len(apiRecs) {
+			body.AppendNewline()
+		}
+		imp := body.AppendNewBlock("import", nil).Body()
+		imp.SetAttributeTraversal("to", hcl.Traversal{
+			hcl.TraverseRoot{Name: "godaddy-dns_record"},
+			hcl.TraverseAttr{Name: label},
+		})
+		imp.SetAttributeValue("id", cty.StringVal(formatImportID(rDomain, rec)))
+
+		body.AppendNewline()
+		res := body.AppendNewBlock("resource", []string{"godaddy-dns_record", label}).Body()
+		res.SetAttributeValue("domain", cty.StringVal(string(rDomain)))
+		res.SetAttributeValue("type", cty.StringVal(string(rec.Type)))
+		res.SetAttributeValue("name", cty.StringVal(string(rec.Name)))
+		res.SetAttributeValue("data", cty.StringVal(string(rec.Data)))
+		res.SetAttributeValue("ttl", cty.NumberUIntVal(uint64(rec.TTL)))
+		switch rec.Type {
+		case model.REC_MX:
+			res.SetAttributeValue("priority", cty.NumberUIntVal(uint64(rec.Priority)))
+		case model.REC_SRV:
+			res.SetAttributeValue("service", cty.StringVal(string(rec.Service)))
+			res.SetAttributeValue("protocol", cty.StringVal(string(rec.Protocol)))
+			res.SetAttributeValue("port", cty.NumberUIntVal(uint64(rec.Port)))
+			res.SetAttributeValue("priority", cty.NumberUIntVal(uint64(rec.Priority)))
+			res.SetAttributeValue("weight", cty.NumberUIntVal(uint64(rec.Weight)))
+		}
+	}
+	_, err = f.WriteTo(w)
+	return err
+}
+
+// reason to skip record in export, empty if it should be exported
+func exportSkipReason(rec model.DNSRecord) string {
+	switch {
+	case slices.ContainsFunc(exportSkip, func(f zoneIgnoreFilter) bool { return f.Matches(rec) }):
+		return "managed by GoDaddy"
+	case rec.Type == model.REC_A && rec.Data == PARKED_DATA:
+		return "parking"
+	case !slices.Contains(importTypes, rec.Type):
+		return "unsupported type"
+	}
+	return ""
+}
+
+// unique resource label like `a_www` or `mx_root_2`
+func exportLabel(rec model.DNSRecord, seen map[string]bool) string {
+	name := strings.ToLower(string(rec.Name))
+	if name == "@" {
+		name = "root"
+	}
+	base := strings.Trim(exportLabelRe.ReplaceAllString(
+		strings.ToLower(string(rec.Type))+"_"+name, "_"), "_")
+	label := base
+	for i := 2; seen[label]; i++ {
+		label = fmt.Sprintf("%s_%d", base, i)
+	}
+	seen[label] = true
+	return label
+}
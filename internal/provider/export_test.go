@@ -0,0 +1,157 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/stretchr/testify/mock"
+	"github.com/veksh/terraform-provider-godaddy-dns/internal/model"
+)
+
+func TestExportDomain(t *testing.T) {
+	t.Parallel()
+	mClient := model.NewMockDNSApiClient(t)
+	mClient.EXPECT().GetRecords(mock.Anything, model.DNSDomain("test.com"), model.DNSRecordType(""), model.DNSRecordName("")).
+		Return([]model.DNSRecord{
+			{Type: model.REC_SOA, Name: "@", Data: "ns1.domaincontrol.com", TTL: 600},
+			{Type: model.REC_NS, Name: "@", Data: "ns1.domaincontrol.com", TTL: 3600},
+			{Type: model.REC_A, Name: "@", Data: "Parked", TTL: 600},
+			{Type: model.REC_CNAME, Name: "_domainconnect", Data: "_domainconnect.gd.domaincontrol.com", TTL: 3600},
+			{Type: model.REC_A, Name: "www", Data: "1.2.3.4", TTL: 600},
+			{Type: model.REC_AAAA, Name: "www", Data: "2001:db8::1", TTL: 3600},
+			{Type: model.REC_TXT, Name: "@", Data: `v=spf1 -all`, TTL: 3600},
+			{Type: model.REC_TXT, Name: "@", Data: `v=1 "quoted" ${not_var} %{not_directive}`, TTL: 3600},
+			{Type: model.REC_MX, Name: "@", Data: "mx1.test.com", Priority: 10, TTL: 3600},
+			{Type: model.REC_NS, Name: "sub", Data: "ns1.other.com", TTL: 3600},
+			{Type: model.REC_SRV, Name: "@", Data: "sip.test.com", TTL: 3600,
+				Service: "_sip", Protocol: "_tcp", Port: 5060, Priority: 10, Weight: 5},
+			{Type: "PTR", Name: "1", Data: "host.test.com", TTL: 3600},
+		}, nil).Once()
+
+	var out bytes.Buffer
+	if err := ExportDomain(context.Background(), mClient, "test.com", &out); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"# skipped (managed by GoDaddy): test.com:SOA:@:",
+		"# skipped (managed by GoDaddy): test.com:NS:@:",
+		"# skipped (managed by GoDaddy): test.com:CNAME:_domainconnect:",
+		"# skipped (parking): test.com:A:@:Parked",
+		"# skipped (unsupported type): test.com:PTR:1:",
+		"to = godaddy-dns_record.a_www\n",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("want %q in output:\n%s", want, out.String())
+		}
+	}
+
+	file, diags := hclsyntax.ParseConfig(out.Bytes(), "export.tf", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatalf("output is not valid HCL: %s\n%s", diags, out.String())
+	}
+	resources := map[string]map[string]string{}
+	imports := map[string]string{}
+	for _, block := range file.Body.(*hclsyntax.Body).Blocks {
+		attrs := map[string]string{}
+		for name, attr := range block.Body.Attributes {
+			if name == "to" {
+				trav := attr.Expr.(*hclsyntax.ScopeTraversalExpr).Traversal
+				attrs[name] = trav.RootName() + "." + trav[1].(hcl.TraverseAttr).Name
+				continue
+			}
+			val, diags := attr.Expr.Value(nil)
+			if diags.HasErrors() {
+				t.Fatalf("%s: %s", name, diags)
+			}
+			if val.Type().FriendlyName() == "number" {
+				attrs[name] = val.AsBigFloat().String()
+			} else {
+				attrs[name] = val.AsString()
+			}
+		}
+		switch block.Type {
+		case "import":
+			imports[attrs["to"]] = attrs["id"]
+		case "resource":
+			resources["godaddy-dns_record."+block.Labels[1]] = attrs
+		}
+	}
+
+	wantLabels := []string{"a_www", "aaaa_www", "mx_root", "ns_sub", "srv_root", "txt_root", "txt_root_2"}
+	if len(resources) != len(wantLabels) || len(imports) != len(wantLabels) {
+		t.Errorf("want %d resources and imports, got %d and %d", len(wantLabels), len(resources), len(imports))
+	}
+	for _, label := range wantLabels {
+		addr := "godaddy-dns_record." + label
+		res, ok := resources[addr]
+		if !ok {
+			t.Errorf("missing resource %s", addr)
+			continue
+		}
+		key, err := parseImportID(imports[addr])
+		if err != nil {
+			t.Errorf("%s: bad import id %q: %s", addr, imports[addr], err)
+			continue
+		}
+		if res["domain"] != string(key.Domain) || res["type"] != string(key.Record.Type) ||
+			res["name"] != string(key.Record.Name) || res["data"] != string(key.Record.Data) {
+			t.Errorf("%s: import id %q does not match resource %v", addr, imports[addr], res)
+		}
+	}
+
+	if diff := cmp.Diff(map[string]string{
+		"domain": "test.com", "type": "TXT", "name": "@", "ttl": "3600",
+		"data": `v=1 "quoted" ${not_var} %{not_directive}`,
+	}, resources["godaddy-dns_record.txt_root"]); diff != "" {
+		t.Errorf("TXT with template sequences: %s", diff)
+	}
+	if diff := cmp.Diff(map[string]string{
+		"domain": "test.com", "type": "SRV", "name": "@", "data": "sip.test.com", "ttl": "3600",
+		"service": "_sip", "protocol": "_tcp", "port": "5060", "priority": "10", "weight": "5",
+	}, resources["godaddy-dns_record.srv_root"]); diff != "" {
+		t.Errorf("SRV: %s", diff)
+	}
+	if resources["godaddy-dns_record.mx_root"]["priority"] != "10" {
+		t.Errorf("MX priority is not exported: %v", resources["godaddy-dns_record.mx_root"])
+	}
+}
+
+func TestExportDomain_APIError(t *testing.T) {
+	t.Parallel()
+	mClient := model.NewMockDNSApiClient(t)
+	mClient.EXPECT().GetRecords(mock.Anything, model.DNSDomain("test.com"), model.DNSRecordType(""), model.DNSRecordName("")).
+		Return(nil, errors.New("boom")).Once()
+
+	var out bytes.Buffer
+	if err := ExportDomain(context.Background(), mClient, "test.com", &out); err == nil {
+		t.Error("want error from API to be returned")
+	}
+	if out.Len() != 0 {
+		t.Errorf("want no output on error, got %q", out.String())
+	}
+}
+
+func TestExportLabel(t *testing.T) {
+	t.Parallel()
+	seen := map[string]bool{}
+	for _, tc := range []struct {
+		rec  model.DNSRecord
+		want string
+	}{
+		{model.DNSRecord{Type: model.REC_A, Name: "@"}, "a_root"},
+		{model.DNSRecord{Type: model.REC_A, Name: "@"}, "a_root_2"},
+		{model.DNSRecord{Type: model.REC_A, Name: "@"}, "a_root_3"},
+		{model.DNSRecord{Type: model.REC_TXT, Name: "_acme-challenge.www"}, "txt_acme-challenge_www"},
+		{model.DNSRecord{Type: model.REC_CNAME, Name: "*.WWW"}, "cname_www"},
+	} {
+		if got := exportLabel(tc.rec, seen); got != tc.want {
+			t.Errorf("label for %s %s: want %q, got %q", tc.rec.Type, tc.rec.Name, tc.want, got)
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/veksh/terraform-provider-godaddy-dns/internal/model"
+)
+
+// record import identifier: `<domain>:<TYPE>:<name>[:<keys>][:<data>]`,
+// fields are separated by IMPORT_SEP
+//   - keys are the rest of record identity besides data (see SameKey):
+//     `<service>:<protocol>:<port>` for SRV (required with data), optional
+//     `<priority>` for MX (it is not a part of the key, so it is just informational)
+//   - data could be omitted to take it from API, if it is unambiguous; CAA
+//     data could be without flags (`<tag> "<value>"`), they are taken from API
+//   - `\:` and `\\` are escapes for separator and backslash inside a field;
+//     data is the last field, so separators in it (IPv6, TXT) could be left
+//     unescaped too: all the remaining fields are joined back
+//
+// e.g. mydom.com:AAAA:www:2001:db8::1, mydom.com:MX:@:10:mx1.mydom.com,
+// mydom.com:SRV:@:_sip:_tcp:5060:sip.mydom.com
+type importKey struct {
+	Domain model.DNSDomain
+	// type, name and key fields; data is empty if not set
+	Record model.DNSRecord
+	// for MX: priority is set
+	HasPriority bool
+	// for CAA: data is without flags, full data must be taken from API
+	NoFlags bool
+}
+
+// record types supported by record resource
+var importTypes = []model.DNSRecordType{
+	model.REC_A, model.REC_AAAA, model.REC_CAA, model.REC_CNAME,
+	model.REC_MX, model.REC_NS, model.REC_SRV, model.REC_TXT,
+}
+
+var (
+	srvServiceRe  = regexp.MustCompile(`^_[a-zA-Z0-9-]+$`)
+	mxPriorityRe  = regexp.MustCompile(`^[0-9]+$`)
+	srvProtocols  = []string{"_tcp", "_udp", "_tls"}
+	importEscaper = strings.NewReplacer(`\`, `\\`, IMPORT_SEP, `\`+IMPORT_SEP)
+)
+
+// split id into fields on unescaped separators, unescaping the fields
+func splitImportID(id string) []string {
+	fields := []string{}
+	var field strings.Builder
+	for i := 0; i < len(id); i++ {
+		switch {
+		case id[i] == '\\' && i+1 < len(id) && (id[i+1] == '\\' || id[i+1] == IMPORT_SEP[0]):
+			i++
+			field.WriteByte(id[i])
+		case id[i] == IMPORT_SEP[0]:
+			fields = append(fields, field.String())
+			field.Reset()
+		default:
+			field.WriteByte(id[i])
+		}
+	}
+	return append(fields, field.String())
+}
+
+// parse import identifier, checking every part; errors for all the bad
+// parts are returned together
+func parseImportID(id string) (importKey, error) {
+	fields := splitImportID(id)
+	if len(fields) < 3 {
+		return importKey{}, fmt.Errorf("expected at least <domain>:<TYPE>:<name>, got %d field(s)", len(fields))
+	}
+	errs := []error{}
+	key := importKey{
+		Domain: model.DNSDomain(fields[0]),
+		Record: model.DNSRecord{
+			Type: model.DNSRecordType(fields[1]),
+			Name: model.DNSRecordName(fields[2]),
+		},
+	}
+	if err := model.ValidateHostName(fields[0]); err != nil {
+		errs = append(errs, fmt.Errorf("domain: %w", err))
+	}
+	if !slices.Contains(importTypes, key.Record.Type) {
+		errs = append(errs, fmt.Errorf("type: must be one of %v, got %q", importTypes, fields[1]))
+	}
+	if fields[2] == "" {
+		errs = append(errs, errors.New("name: must not be empty, use @ for domain itself"))
+	}
+	rest := fields[3:]
+
+	switch key.Record.Type {
+	case model.REC_SRV:
+		if len(rest) == 0 {
+			break
+		}
+		if len(rest) < 3 {
+			errs = append(errs, errors.New("SRV keys: expected <service>:<protocol>:<port> after name"))
+			break
+		}
+		if !srvServiceRe.MatchString(rest[0]) {
+			errs = append(errs, fmt.Errorf("service: must be like _sip, got %q", rest[0]))
+		}
+		if !slices.Contains(srvProtocols, rest[1]) {
+			errs = append(errs, fmt.Errorf("protocol: must be one of %v, got %q", srvProtocols, rest[1]))
+		}
+		port, err := strconv.ParseUint(rest[2], 10, 16)
+		if err != nil || port == 0 {
+			errs = append(errs, fmt.Errorf("port: must be a number in 1-65535 range, got %q", rest[2]))
+		}
+		key.Record.Service = model.DNSRecordSRVService(rest[0])
+		key.Record.Protocol = model.DNSRecordSRVProto(rest[1])
+		key.Record.Port = model.DNSRecordSRVPort(port)
+		rest = rest[3:]
+	case model.REC_MX:
+		// host names have no separators, so 2 fields are priority + data;
+		// single numeric one is priority without data (top-level domain
+		// names are not numeric, so it could not be a host name)
+		if len(rest) == 2 || len(rest) == 1 && mxPriorityRe.MatchString(rest[0]) {
+			prio, err := strconv.ParseUint(rest[0], 10, 16)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("priority: must be a number in 0-65535 range, got %q", rest[0]))
+			}
+			key.Record.Priority = model.DNSRecordPrio(prio)
+			key.HasPriority = true
+			rest = rest[1:]
+		}
+	}
+
+	if len(rest) > 0 {
+		key.Record.Data = model.DNSRecordData(strings.Join(rest, IMPORT_SEP))
+		switch {
+		case key.Record.Data == "":
+			errs = append(errs, errors.New("data: must not be empty, omit it to take from API"))
+		case key.Record.Type == model.REC_CAA:
+			if _, noFlags, err := parseCAAKeyData(key.Record.Data); err != nil {
+				errs = append(errs, fmt.Errorf("data: %w", err))
+			} else {
+				key.NoFlags = noFlags
+			}
+		default:
+			if err := model.ValidateData(key.Record.Type, key.Record.Data); err != nil {
+				errs = append(errs, fmt.Errorf("data: %w", err))
+			}
+		}
+	}
+	return key, errors.Join(errs...)
+}
+
+// import identifier for record: parses back into the same key
+func formatImportID(rDomain model.DNSDomain, rec model.DNSRecord) string {
+	parts := []string{string(rDomain), string(rec.Type), string(rec.Name)}
+	switch rec.Type {
+	case model.REC_SRV:
+		parts = append(parts, string(rec.Service), string(rec.Protocol), strconv.Itoa(int(rec.Port)))
+	case model.REC_MX:
+		parts = append(parts, strconv.Itoa(int(rec.Priority)))
+	}
+	return joinImportID(append(parts, string(rec.Data)))
+}
+
+// stable record id: the shortest import identifier for record key (see
+// SameKey), so it does not change on in-place updates of value fields:
+// no MX priority, no CAA flags, and no data for CNAME (there is only one
+// for the name)
+func recordID(rDomain model.DNSDomain, rec model.DNSRecord) string {
+	parts := []string{string(rDomain), string(rec.Type), string(rec.Name)}
+	if rec.Type == model.REC_SRV {
+		parts = append(parts, string(rec.Service), string(rec.Protocol), strconv.Itoa(int(rec.Port)))
+	}
+	switch {
+	case rec.Type.IsSingleValue():
+		// only one record for the name, no data
+	case rec.Type == model.REC_CAA:
+		if caa, err := model.ParseCAAData(rec.Data); err == nil {
+			parts = append(parts, fmt.Sprintf("%s %q", strings.ToLower(caa.Tag), caa.Value))
+			break
+		}
+		parts = append(parts, string(rec.Data))
+	default:
+		parts = append(parts, string(rec.Data))
+	}
+	return joinImportID(parts)
+}
+
+// CAA data in import id: `<flags> <tag> "<value>"`, or `<tag> "<value>"`
+// without flags, as they are not a part of the key
+func parseCAAKeyData(d model.DNSRecordData) (caa model.CAAData, noFlags bool, err error) {
+	if fields := strings.Fields(string(d)); len(fields) > 0 && !mxPriorityRe.MatchString(fields[0]) {
+		caa, err = model.ParseCAAData("0 " + d)
+		return caa, true, err
+	}
+	caa, err = model.ParseCAAData(d)
+	return caa, false, err
+}
+
+// escape fields and join them with separator
+func joinImportID(parts []string) string {
+	for i, p := range parts {
+		parts[i] = importEscaper.Replace(p)
+	}
+	return strings.Join(parts, IMPORT_SEP)
+}
+
+// true if API record matches import key: by key fields and data (if set),
+// CAA data is matched by tag and value
+func (k importKey) matches(rec model.DNSRecord) bool {
+	if k.Record.Type == model.REC_SRV && k.Record.Port != 0 && (rec.Service != k.Record.Service ||
+		rec.Protocol != k.Record.Protocol || rec.Port != k.Record.Port) {
+		return false
+	}
+	if k.HasPriority && rec.Priority != k.Record.Priority {
+		return false
+	}
+	if k.Record.Data == "" {
+		return true
+	}
+	if rec.Type == model.REC_CAA {
+		caa, err := model.ParseCAAData(rec.Data)
+		keyCAA, _, keyErr := parseCAAKeyData(k.Record.Data)
+		if err == nil && keyErr == nil {
+			return caa.SameKey(keyCAA)
+		}
+	}
+	return model.EquivalentData(rec.Type, rec.Data, k.Record.Data)
+}
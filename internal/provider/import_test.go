@@ -0,0 +1,360 @@
+package provider
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/stretchr/testify/mock"
+	"github.com/veksh/terraform-provider-godaddy-dns/internal/model"
+)
+
+// run record import with given client, return resulting state
+func importRecord(t *testing.T, c model.DNSApiClient, id string) (tfDNSRecord, resource.ImportStateResponse) {
+	t.Helper()
+	ctx := context.Background()
+	conf := recordConfig(t, nil)
+	resp := resource.ImportStateResponse{
+		State: tfsdk.State{Schema: conf.Schema, Raw: conf.Raw},
+	}
+	r := &RecordResource{client: c}
+	r.ImportState(ctx, resource.ImportStateRequest{ID: id}, &resp)
+	var res tfDNSRecord
+	if !resp.Diagnostics.HasError() {
+		resp.Diagnostics.Append(resp.State.Get(ctx, &res)...)
+	}
+	return res, resp
+}
+
+func TestImportState_WithoutData(t *testing.T) {
+	t.Parallel()
+	srv := model.DNSRecord{Type: model.REC_SRV, Name: "@", Data: "sip.test.com",
+		Service: "_sip", Protocol: "_tcp", Port: 5060, Priority: 10, Weight: 5, TTL: 3600}
+	mClient := model.NewMockDNSApiClient(t)
+	mClient.EXPECT().GetRecords(mock.Anything, model.DNSDomain("test.com"), model.REC_CNAME, model.DNSRecordName("www")).
+		Return([]model.DNSRecord{{Type: model.REC_CNAME, Name: "www", Data: "other.com", TTL: 3600}}, nil).Once()
+	mClient.EXPECT().GetRecords(mock.Anything, model.DNSDomain("test.com"), model.REC_SRV, model.DNSRecordName("@")).
+		Return([]model.DNSRecord{srv}, nil).Once()
+
+	got, resp := importRecord(t, mClient, "test.com:CNAME:www")
+	if resp.Diagnostics.HasError() {
+		t.Fatal(resp.Diagnostics)
+	}
+	if got.Data.ValueString() != "other.com" || got.Name.ValueString() != "www" {
+		t.Errorf("unexpected CNAME import result: %+v", got)
+	}
+	if got.ID.ValueString() != "test.com:CNAME:www" {
+		t.Errorf("unexpected CNAME import id: %s", got.ID)
+	}
+
+	got, resp = importRecord(t, mClient, "test.com:SRV:@")
+	if resp.Diagnostics.HasError() {
+		t.Fatal(resp.Diagnostics)
+	}
+	if got.Data.ValueString() != "sip.test.com" || got.Service.ValueString() != "_sip" ||
+		got.Protocol.ValueString() != "_tcp" || got.Port.ValueInt64() != 5060 {
+		t.Errorf("unexpected SRV import result: %+v", got)
+	}
+	if got.ID.ValueString() != "test.com:SRV:@:_sip:_tcp:5060:sip.test.com" {
+		t.Errorf("unexpected SRV import id: %s", got.ID)
+	}
+}
+
+func TestImportState_MXByPriority(t *testing.T) {
+	t.Parallel()
+	mClient := model.NewMockDNSApiClient(t)
+	mClient.EXPECT().GetRecords(mock.Anything, model.DNSDomain("test.com"), model.REC_MX, model.DNSRecordName("@")).
+		Return([]model.DNSRecord{
+			{Type: model.REC_MX, Name: "@", Data: "mx1.test.com", Priority: 10, TTL: 3600},
+			{Type: model.REC_MX, Name: "@", Data: "mx2.test.com", Priority: 20, TTL: 3600},
+		}, nil).Once()
+
+	got, resp := importRecord(t, mClient, "test.com:MX:@:20")
+	if resp.Diagnostics.HasError() {
+		t.Fatal(resp.Diagnostics)
+	}
+	if got.Data.ValueString() != "mx2.test.com" || got.Priority.ValueInt64() != 20 {
+		t.Errorf("unexpected MX import result: %+v", got)
+	}
+}
+
+// flags are not a part of CAA key (and id), so they are taken from API
+func TestImportState_CAAWithoutFlags(t *testing.T) {
+	t.Parallel()
+	mClient := model.NewMockDNSApiClient(t)
+	mClient.EXPECT().GetRecords(mock.Anything, model.DNSDomain("test.com"), model.REC_CAA, model.DNSRecordName("@")).
+		Return([]model.DNSRecord{
+			{Type: model.REC_CAA, Name: "@", Data: `0 issuewild "letsencrypt.org"`, TTL: 3600},
+			{Type: model.REC_CAA, Name: "@", Data: `128 issue "letsencrypt.org"`, TTL: 3600},
+		}, nil).Once()
+
+	id := `test.com:CAA:@:issue "letsencrypt.org"`
+	got, resp := importRecord(t, mClient, id)
+	if resp.Diagnostics.HasError() {
+		t.Fatal(resp.Diagnostics)
+	}
+	if got.Data.ValueString() != `128 issue "letsencrypt.org"` || got.ID.ValueString() != id {
+		t.Errorf("unexpected CAA import result: %+v", got)
+	}
+}
+
+func TestImportState_Ambiguous(t *testing.T) {
+	t.Parallel()
+	mClient := model.NewMockDNSApiClient(t)
+	mClient.EXPECT().GetRecords(mock.Anything, model.DNSDomain("test.com"), model.REC_TXT, model.DNSRecordName("@")).
+		Return([]model.DNSRecord{
+			{Type: model.REC_TXT, Name: "@", Data: "v=spf1 -all", TTL: 3600},
+			{Type: model.REC_TXT, Name: "@", Data: "google-site-verification=xxx", TTL: 3600},
+		}, nil).Once()
+	mClient.EXPECT().GetRecords(mock.Anything, model.DNSDomain("test.com"), model.REC_TXT, model.DNSRecordName("gone")).
+		Return([]model.DNSRecord{}, nil).Once()
+
+	_, resp := importRecord(t, mClient, "test.com:TXT:@")
+	if !resp.Diagnostics.HasError() {
+		t.Fatal("want error for ambiguous import")
+	}
+	detail := resp.Diagnostics.Errors()[0].Detail()
+	for _, want := range []string{"test.com:TXT:@:v=spf1 -all", "test.com:TXT:@:google-site-verification=xxx"} {
+		if !strings.Contains(detail, want) {
+			t.Errorf("candidate %q is not listed in %q", want, detail)
+		}
+	}
+
+	if _, resp = importRecord(t, mClient, "test.com:TXT:gone"); !resp.Diagnostics.HasError() {
+		t.Error("want error for missing record")
+	}
+}
+
+func TestImportState_BadID(t *testing.T) {
+	t.Parallel()
+	mClient := model.NewMockDNSApiClient(t)
+	for _, id := range []string{"test.com", "test.com:CNAME", "test.com::www", ":CNAME:www"} {
+		if _, resp := importRecord(t, mClient, id); !resp.Diagnostics.HasError() {
+			t.Errorf("want error for import id %q", id)
+		}
+	}
+}
+
+func TestParseImportID(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		id      string
+		want    importKey
+		wantErr []string
+	}{
+		{
+			name: "AAAA unescaped",
+			id:   "test.com:AAAA:www:2001:db8::1",
+			want: importKey{Domain: "test.com", Record: model.DNSRecord{Type: model.REC_AAAA, Name: "www", Data: "2001:db8::1"}},
+		},
+		{
+			name: "AAAA escaped",
+			id:   `test.com:AAAA:www:2001\:db8\:\:1`,
+			want: importKey{Domain: "test.com", Record: model.DNSRecord{Type: model.REC_AAAA, Name: "www", Data: "2001:db8::1"}},
+		},
+		{
+			name: "TXT with colons",
+			id:   "test.com:TXT:_test:key: value: more",
+			want: importKey{Domain: "test.com", Record: model.DNSRecord{Type: model.REC_TXT, Name: "_test", Data: "key: value: more"}},
+		},
+		{
+			name: "TXT with escapes",
+			id:   `test.com:TXT:_test:back\\slash\:colon\n`,
+			want: importKey{Domain: "test.com", Record: model.DNSRecord{Type: model.REC_TXT, Name: "_test", Data: `back\slash:colon\n`}},
+		},
+		{
+			name: "CNAME without data",
+			id:   "test.com:CNAME:www",
+			want: importKey{Domain: "test.com", Record: model.DNSRecord{Type: model.REC_CNAME, Name: "www"}},
+		},
+		{
+			name: "MX without priority",
+			id:   "test.com:MX:@:mx1.test.com",
+			want: importKey{Domain: "test.com", Record: model.DNSRecord{Type: model.REC_MX, Name: "@", Data: "mx1.test.com"}},
+		},
+		{
+			name: "MX with priority",
+			id:   "test.com:MX:@:10:mx1.test.com",
+			want: importKey{Domain: "test.com", Record: model.DNSRecord{Type: model.REC_MX, Name: "@", Data: "mx1.test.com", Priority: 10}, HasPriority: true},
+		},
+		{
+			name: "MX with priority without data",
+			id:   "test.com:MX:@:10",
+			want: importKey{Domain: "test.com", Record: model.DNSRecord{Type: model.REC_MX, Name: "@", Priority: 10}, HasPriority: true},
+		},
+		{
+			name:    "MX with priority out of range",
+			id:      "test.com:MX:@:70000",
+			wantErr: []string{"priority:"},
+		},
+		{
+			name: "SRV",
+			id:   "test.com:SRV:@:_sip:_tcp:5060:sip.test.com",
+			want: importKey{Domain: "test.com", Record: model.DNSRecord{Type: model.REC_SRV, Name: "@", Data: "sip.test.com",
+				Service: "_sip", Protocol: "_tcp", Port: 5060}},
+		},
+		{
+			name: "SRV keys without data",
+			id:   "test.com:SRV:@:_sip:_tcp:5060",
+			want: importKey{Domain: "test.com", Record: model.DNSRecord{Type: model.REC_SRV, Name: "@",
+				Service: "_sip", Protocol: "_tcp", Port: 5060}},
+		},
+		{
+			name: "CAA with flags",
+			id:   `test.com:CAA:@:128 issue "letsencrypt.org"`,
+			want: importKey{Domain: "test.com", Record: model.DNSRecord{Type: model.REC_CAA, Name: "@",
+				Data: `128 issue "letsencrypt.org"`}},
+		},
+		{
+			name: "CAA without flags",
+			id:   `test.com:CAA:@:issue "letsencrypt.org"`,
+			want: importKey{Domain: "test.com", Record: model.DNSRecord{Type: model.REC_CAA, Name: "@",
+				Data: `issue "letsencrypt.org"`}, NoFlags: true},
+		},
+		{
+			name:    "CAA with bad flags",
+			id:      `test.com:CAA:@:300 issue "letsencrypt.org"`,
+			wantErr: []string{"data:"},
+		},
+		{
+			name:    "too short",
+			id:      "test.com:A",
+			wantErr: []string{"at least"},
+		},
+		{
+			name:    "bad domain and type",
+			id:      "test com:PTR:www:1.2.3.4",
+			wantErr: []string{"domain:", "type:"},
+		},
+		{
+			name:    "empty name",
+			id:      "test.com:A::1.2.3.4",
+			wantErr: []string{"name:"},
+		},
+		{
+			name:    "bad data",
+			id:      "test.com:A:www:2001:db8::1",
+			wantErr: []string{"data:"},
+		},
+		{
+			name:    "empty data",
+			id:      "test.com:A:www:",
+			wantErr: []string{"data:"},
+		},
+		{
+			name:    "bad MX priority",
+			id:      "test.com:MX:@:high:mx1.test.com",
+			wantErr: []string{"priority:"},
+		},
+		{
+			name:    "bad SRV keys",
+			id:      "test.com:SRV:@:sip:_sctp:0:sip.test.com",
+			wantErr: []string{"service:", "protocol:", "port:"},
+		},
+		{
+			name:    "incomplete SRV keys",
+			id:      "test.com:SRV:@:_sip:_tcp",
+			wantErr: []string{"SRV keys:"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := parseImportID(tt.id)
+			if len(tt.wantErr) > 0 {
+				if err == nil {
+					t.Fatalf("want error for %q", tt.id)
+				}
+				for _, want := range tt.wantErr {
+					if !strings.Contains(err.Error(), want) {
+						t.Errorf("want %q in error, got %q", want, err)
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !cmp.Equal(tt.want, got) {
+				t.Error(cmp.Diff(tt.want, got))
+			}
+		})
+	}
+}
+
+func TestFormatImportID_RoundTrip(t *testing.T) {
+	t.Parallel()
+	recs := []model.DNSRecord{
+		{Type: model.REC_A, Name: "www", Data: "1.2.3.4"},
+		{Type: model.REC_AAAA, Name: "www", Data: "2001:db8::1"},
+		{Type: model.REC_TXT, Name: "_test", Data: `v=1: "quoted" \: back\slash`},
+		{Type: model.REC_CNAME, Name: "alias", Data: "test.com"},
+		{Type: model.REC_CAA, Name: "@", Data: `0 iodef "mailto:sec@test.com"`},
+		{Type: model.REC_MX, Name: "@", Data: "mx1.test.com", Priority: 10},
+		{Type: model.REC_SRV, Name: "@", Data: "sip.test.com", Service: "_sip", Protocol: "_tcp", Port: 5060},
+	}
+	for _, rec := range recs {
+		id := formatImportID("test.com", rec)
+		key, err := parseImportID(id)
+		if err != nil {
+			t.Errorf("%s: %s", id, err)
+			continue
+		}
+		if key.Domain != "test.com" || !cmp.Equal(rec, key.Record) {
+			t.Errorf("%s: %s", id, cmp.Diff(rec, key.Record))
+		}
+	}
+}
+
+func TestRecordID(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		rec  model.DNSRecord
+		want string
+	}{
+		{model.DNSRecord{Type: model.REC_A, Name: "www", Data: "1.2.3.4", TTL: 600}, "test.com:A:www:1.2.3.4"},
+		{model.DNSRecord{Type: model.REC_CNAME, Name: "www", Data: "other.com"}, "test.com:CNAME:www"},
+		{model.DNSRecord{Type: model.REC_MX, Name: "@", Data: "mx1.test.com", Priority: 10}, "test.com:MX:@:mx1.test.com"},
+		{model.DNSRecord{Type: model.REC_TXT, Name: "_test", Data: "key: value"}, `test.com:TXT:_test:key\: value`},
+		{model.DNSRecord{Type: model.REC_SRV, Name: "@", Data: "sip.test.com", Priority: 10, Weight: 5,
+			Service: "_sip", Protocol: "_tcp", Port: 5060}, "test.com:SRV:@:_sip:_tcp:5060:sip.test.com"},
+		{model.DNSRecord{Type: model.REC_CAA, Name: "@", Data: `0 Issue "letsencrypt.org"`},
+			`test.com:CAA:@:issue "letsencrypt.org"`},
+		{model.DNSRecord{Type: model.REC_CAA, Name: "@", Data: `0 iodef "mailto:sec@test.com"`},
+			`test.com:CAA:@:iodef "mailto\:sec@test.com"`},
+	}
+	for _, tt := range tests {
+		got := recordID("test.com", tt.rec)
+		if got != tt.want {
+			t.Errorf("want id %q, got %q", tt.want, got)
+		}
+		// id could be used for import
+		key, err := parseImportID(got)
+		if err != nil {
+			t.Errorf("%s: %s", got, err)
+			continue
+		}
+		if !key.matches(tt.rec) {
+			t.Errorf("%s: does not match record %v", got, tt.rec)
+		}
+	}
+}
+
+// CAA flags are updated in place, so they must not change the id
+func TestRecordID_CAAFlags(t *testing.T) {
+	t.Parallel()
+	rec := model.DNSRecord{Type: model.REC_CAA, Name: "@", Data: `0 issue "letsencrypt.org"`}
+	critical := rec
+	critical.Data = `128 issue "letsencrypt.org"`
+	if !rec.SameKey(critical) {
+		t.Fatal("flags-only change must keep the record key")
+	}
+	if id, idCritical := recordID("test.com", rec), recordID("test.com", critical); id != idCritical {
+		t.Errorf("flags-only change changed id from %q to %q", id, idCritical)
+	}
+}
@@ -0,0 +1,91 @@
+package provider
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/veksh/terraform-provider-godaddy-dns/internal/model"
+)
+
+// lock manager for API operations
+//   - read-modify-write of records is only racy for the same domain + type + name
+//     (API replaces all the values at once), so only those are serialized and
+//     unrelated records are processed in parallel
+//   - zone-wide operations lock the whole domain, waiting for record operations
+//     in it to finish
+//   - locks are reference-counted and dropped when not in use
+type recordLocker struct {
+	mu    sync.Mutex
+	locks map[lockKey]*lockEntry
+}
+
+// type and name are empty for domain lock
+type lockKey struct {
+	Domain model.DNSDomain
+	Type   model.DNSRecordType
+	Name   model.DNSRecordName
+}
+
+type lockEntry struct {
+	mu   sync.RWMutex
+	refs int
+}
+
+func newRecordLocker() *recordLocker {
+	return &recordLocker{locks: map[lockKey]*lockEntry{}}
+}
+
+// lock records of type + name in domain, returns unlock function
+func (l *recordLocker) Lock(rDomain model.DNSDomain, rType model.DNSRecordType, rName model.DNSRecordName) func() {
+	domainKey := lockKey{Domain: model.DNSDomain(strings.ToLower(string(rDomain)))}
+	domainLock := l.acquire(domainKey)
+	domainLock.mu.RLock()
+
+	recKey := lockKey{
+		Domain: domainKey.Domain,
+		Type:   rType,
+		Name:   model.DNSRecordName(strings.ToLower(string(rName))),
+	}
+	recLock := l.acquire(recKey)
+	recLock.mu.Lock()
+
+	return func() {
+		recLock.mu.Unlock()
+		l.release(recKey, recLock)
+		domainLock.mu.RUnlock()
+		l.release(domainKey, domainLock)
+	}
+}
+
+// lock all the records in domain, returns unlock function
+func (l *recordLocker) LockDomain(rDomain model.DNSDomain) func() {
+	domainKey := lockKey{Domain: model.DNSDomain(strings.ToLower(string(rDomain)))}
+	domainLock := l.acquire(domainKey)
+	domainLock.mu.Lock()
+
+	return func() {
+		domainLock.mu.Unlock()
+		l.release(domainKey, domainLock)
+	}
+}
+
+func (l *recordLocker) acquire(key lockKey) *lockEntry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	entry, ok := l.locks[key]
+	if !ok {
+		entry = &lockEntry{}
+		l.locks[key] = entry
+	}
+	entry.refs++
+	return entry
+}
+
+func (l *recordLocker) release(key lockKey, entry *lockEntry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	entry.refs--
+	if entry.refs == 0 {
+		delete(l.locks, key)
+	}
+}
@@ -0,0 +1,138 @@
+package provider
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/veksh/terraform-provider-godaddy-dns/internal/model"
+)
+
+const lockWait = 50 * time.Millisecond
+
+// run lock function in background, return channel closed when lock is acquired
+func lockAsync(lock func() func(), unlocks chan<- func()) <-chan struct{} {
+	locked := make(chan struct{})
+	go func() {
+		unlock := lock()
+		close(locked)
+		unlocks <- unlock
+	}()
+	return locked
+}
+
+func TestRecordLocker_IndependentKeysInParallel(t *testing.T) {
+	t.Parallel()
+	l := newRecordLocker()
+	unlocks := make(chan func(), 10)
+
+	unlock := l.Lock("test.com", model.REC_MX, "@")
+	defer unlock()
+	for _, lock := range []func() func(){
+		// other name
+		func() func() { return l.Lock("test.com", model.REC_MX, "mail") },
+		// other type
+		func() func() { return l.Lock("test.com", model.REC_TXT, "@") },
+		// other domain
+		func() func() { return l.Lock("other.com", model.REC_MX, "@") },
+		// whole other domain
+		func() func() { return l.LockDomain("other.com") },
+	} {
+		select {
+		case <-lockAsync(lock, unlocks):
+			(<-unlocks)()
+		case <-time.After(time.Second):
+			t.Fatal("independent lock is blocked")
+		}
+	}
+}
+
+func TestRecordLocker_SameKeySerialized(t *testing.T) {
+	t.Parallel()
+	l := newRecordLocker()
+	unlocks := make(chan func(), 10)
+
+	unlock := l.Lock("test.com", model.REC_TXT, "_acme")
+	// names are case-insensitive
+	locked := lockAsync(func() func() { return l.Lock("Test.com", model.REC_TXT, "_ACME") }, unlocks)
+	select {
+	case <-locked:
+		t.Fatal("lock for the same key acquired while held")
+	case <-time.After(lockWait):
+	}
+	unlock()
+	select {
+	case <-locked:
+		(<-unlocks)()
+	case <-time.After(time.Second):
+		t.Fatal("lock not acquired after release")
+	}
+	if len(l.locks) != 0 {
+		t.Errorf("unused locks are not released: %v", l.locks)
+	}
+}
+
+func TestRecordLocker_DomainLockWaitsForRecords(t *testing.T) {
+	t.Parallel()
+	l := newRecordLocker()
+	unlocks := make(chan func(), 10)
+
+	unlockRec := l.Lock("test.com", model.REC_A, "www")
+	lockedDomain := lockAsync(func() func() { return l.LockDomain("test.com") }, unlocks)
+	select {
+	case <-lockedDomain:
+		t.Fatal("domain lock acquired while record is locked")
+	case <-time.After(lockWait):
+	}
+	unlockRec()
+	<-lockedDomain
+	unlockDomain := <-unlocks
+
+	lockedRec := lockAsync(func() func() { return l.Lock("test.com", model.REC_A, "www") }, unlocks)
+	select {
+	case <-lockedRec:
+		t.Fatal("record lock acquired while domain is locked")
+	case <-time.After(lockWait):
+	}
+	unlockDomain()
+	<-lockedRec
+	(<-unlocks)()
+}
+
+// concurrent MX updates for the same name never overlap, others do
+func TestRecordLocker_MaxConcurrency(t *testing.T) {
+	t.Parallel()
+	l := newRecordLocker()
+
+	run := func(names []model.DNSRecordName) int32 {
+		var current, maxSeen atomic.Int32
+		var wg sync.WaitGroup
+		for _, name := range names {
+			wg.Add(1)
+			go func(name model.DNSRecordName) {
+				defer wg.Done()
+				unlock := l.Lock("test.com", model.REC_MX, name)
+				defer unlock()
+				n := current.Add(1)
+				for {
+					m := maxSeen.Load()
+					if n <= m || maxSeen.CompareAndSwap(m, n) {
+						break
+					}
+				}
+				time.Sleep(10 * time.Millisecond)
+				current.Add(-1)
+			}(name)
+		}
+		wg.Wait()
+		return maxSeen.Load()
+	}
+
+	if got := run([]model.DNSRecordName{"@", "@", "@", "@", "@"}); got != 1 {
+		t.Errorf("same-name updates overlap: %d at once", got)
+	}
+	if got := run([]model.DNSRecordName{"a", "b", "c", "d", "e"}); got < 2 {
+		t.Errorf("independent updates are serialized")
+	}
+}
@@ -2,31 +2,50 @@ package provider
 
 import (
 	"context"
+	"fmt"
+	"net/url"
 	"os"
-	"sync"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/veksh/terraform-provider-godaddy-dns/internal/client"
 	"github.com/veksh/terraform-provider-godaddy-dns/internal/model"
 )
 
-// testing api at ote is useless
-const GODADDY_API_URL = "https://api.godaddy.com"
+const (
+	GODADDY_API_URL = "https://api.godaddy.com"
+	// test environment: separate accounts and keys, domains are not real
+	GODADDY_OTE_API_URL = "https://api.ote-godaddy.com"
+	ENV_PRODUCTION      = "production"
+	ENV_OTE             = "ote"
+)
 
 // https://pkg.go.dev/github.com/hashicorp/terraform-plugin-framework/provider
 var _ provider.Provider = &GoDaddyDNSProvider{}
 
-type APIClientFactory func(apiURL, apiKey, apiSecret string) (model.DNSApiClient, error)
+type APIClientFactory func(apiURL, apiKey, apiSecret string, opts ...client.Option) (model.DNSApiClient, error)
+
+// passed to resources and data sources on configure
+type providerData struct {
+	client model.DNSApiClient
+	// nil if write batching is disabled
+	coalescer *writeCoalescer
+}
 
 type GoDaddyDNSProvider struct {
 	// "dev" for local testing, "test" for acceptance tests, "v1.2.3" for prod
 	version       string
 	clientFactory APIClientFactory
-	reqMutex      sync.Mutex
+	locker        *recordLocker
 }
 
 func (p *GoDaddyDNSProvider) Metadata(ctx context.Context, req provider.MetadataRequest, resp *provider.MetadataResponse) {
@@ -38,8 +57,24 @@ func (p *GoDaddyDNSProvider) Metadata(ctx context.Context, req provider.Metadata
 
 // have to match schema
 type GoDaddyDNSProviderModel struct {
-	APIKey    types.String `tfsdk:"api_key"`
-	APISecret types.String `tfsdk:"api_secret"`
+	APIKey       types.String `tfsdk:"api_key"`
+	APISecret    types.String `tfsdk:"api_secret"`
+	ShopperID    types.String `tfsdk:"shopper_id"`
+	APIURL       types.String `tfsdk:"api_url"`
+	Environment  types.String `tfsdk:"environment"`
+	MaxRetries   types.Int64  `tfsdk:"max_retries"`
+	MaxRetryWait types.Int64  `tfsdk:"max_retry_wait"`
+	BatchWindow  types.Int64  `tfsdk:"batch_window_ms"`
+	CacheReads   types.Bool   `tfsdk:"cache_reads"`
+	RateLimit    *tfRateLimit `tfsdk:"rate_limit"`
+}
+
+type tfRateLimit struct {
+	Algorithm types.String `tfsdk:"algorithm"`
+	Requests  types.Int64  `tfsdk:"requests"`
+	Period    types.Int64  `tfsdk:"period"`
+	Burst     types.Int64  `tfsdk:"burst"`
+	File      types.String `tfsdk:"file"`
 }
 
 func (p *GoDaddyDNSProvider) Schema(ctx context.Context, req provider.SchemaRequest, resp *provider.SchemaResponse) {
@@ -58,6 +93,105 @@ func (p *GoDaddyDNSProvider) Schema(ctx context.Context, req provider.SchemaRequ
 				Optional:            true,
 				Sensitive:           true,
 			},
+			"shopper_id": schema.StringAttribute{
+				MarkdownDescription: "Shopper ID to act on behalf of reseller sub-account " +
+					"(sent as `X-Shopper-Id`)",
+				Optional: true,
+			},
+			"api_url": schema.StringAttribute{
+				MarkdownDescription: "Base URL of API, like `http://localhost:8080` for local fake " +
+					"server or proxy; default is set by `environment`",
+				Optional: true,
+				Validators: []validator.String{
+					stringvalidator.ConflictsWith(path.MatchRoot("environment")),
+				},
+			},
+			"environment": schema.StringAttribute{
+				MarkdownDescription: "API environment: `production` (default) or `ote` (GoDaddy test environment)",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(ENV_PRODUCTION, ENV_OTE),
+				},
+			},
+			"max_retries": schema.Int64Attribute{
+				MarkdownDescription: "Max number of retries for throttled (429) or failed (5xx) " +
+					"API requests, 0 to disable; default 3",
+				Optional: true,
+				Validators: []validator.Int64{
+					int64validator.AtLeast(0),
+				},
+			},
+			"max_retry_wait": schema.Int64Attribute{
+				MarkdownDescription: "Max wait between retries in seconds; requests are not " +
+					"retried if API asks to wait longer in `Retry-After`; default 30",
+				Optional: true,
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+			"cache_reads": schema.BoolAttribute{
+				MarkdownDescription: "Cache query results during terraform run, so records of the " +
+					"same type + name are read only once; default `true`",
+				Optional: true,
+			},
+			"rate_limit": schema.SingleNestedAttribute{
+				MarkdownDescription: "API requests rate limit; default is GoDaddy limit of 60 requests per minute",
+				Optional:            true,
+				Attributes: map[string]schema.Attribute{
+					"algorithm": schema.StringAttribute{
+						MarkdownDescription: "Rate limiting algorithm: `window` (default: up to `requests` " +
+							"per fixed `period`), `sliding` (up to `requests` in any `period`, without " +
+							"double bursts at window boundary), `bucket` (token bucket: evenly spaced requests with " +
+							"bursts up to `burst`), `file` (sliding window shared between processes " +
+							"via lock file, e.g. for parallel terraform runs), `adaptive` (evenly spaced " +
+							"requests, slowing down on throttling and speeding up to `requests` per `period` " +
+							"while API allows) or `none`",
+						Optional: true,
+						Validators: []validator.String{
+							stringvalidator.OneOf(client.RL_WINDOW, client.RL_SLIDING, client.RL_BUCKET, client.RL_FILE, client.RL_ADAPTIVE, client.RL_NONE),
+						},
+					},
+					"requests": schema.Int64Attribute{
+						MarkdownDescription: "Number of requests per period; default 60",
+						Optional:            true,
+						Validators: []validator.Int64{
+							int64validator.AtLeast(1),
+						},
+					},
+					"period": schema.Int64Attribute{
+						MarkdownDescription: "Period length in seconds; default 60",
+						Optional:            true,
+						Validators: []validator.Int64{
+							int64validator.AtLeast(1),
+						},
+					},
+					"burst": schema.Int64Attribute{
+						MarkdownDescription: "Max burst size for `bucket`; default is `requests`",
+						Optional:            true,
+						Validators: []validator.Int64{
+							int64validator.AtLeast(1),
+						},
+					},
+					"file": schema.StringAttribute{
+						MarkdownDescription: "State file for `file`, must be the same for all the processes " +
+							"sharing the limit; default is a file in temp dir, one per API key and local user",
+						Optional: true,
+						Validators: []validator.String{
+							stringvalidator.LengthAtLeast(1),
+						},
+					},
+				},
+			},
+			"batch_window_ms": schema.Int64Attribute{
+				MarkdownDescription: "Collect changes to values of the same record type + name " +
+					"during this time (in milliseconds) and apply them in one API call; " +
+					"default 0 (disabled): every change is delayed by the window, which pays " +
+					"off only for configs with many values per name",
+				Optional: true,
+				Validators: []validator.Int64{
+					int64validator.Between(0, 10000),
+				},
+			},
 		},
 		// also: Blocks
 	}
@@ -95,30 +229,145 @@ func (p *GoDaddyDNSProvider) Configure(ctx context.Context, req provider.Configu
 		)
 	}
 
+	apiURL, err := apiURLFromConfig(confData)
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid API Endpoint Configuration",
+			"While configuring the provider, API endpoint from api_url and environment "+
+				"attributes or GODADDY_API_URL and GODADDY_ENVIRONMENT environment variables "+
+				"could not be used: "+err.Error())
+	}
+
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	client, err := p.clientFactory(GODADDY_API_URL, apiKey, apiSecret)
+	maxRetries := client.RETRY_MAX
+	if !(confData.MaxRetries.IsUnknown() || confData.MaxRetries.IsNull()) {
+		maxRetries = int(confData.MaxRetries.ValueInt64())
+	}
+	maxRetryWait := client.RETRY_MAX_WAIT
+	if !(confData.MaxRetryWait.IsUnknown() || confData.MaxRetryWait.IsNull()) {
+		maxRetryWait = time.Duration(confData.MaxRetryWait.ValueInt64()) * time.Second
+	}
+	clientOpts := []client.Option{
+		client.WithRetries(maxRetries, min(client.RETRY_MIN_WAIT, maxRetryWait), maxRetryWait),
+	}
+	if confData.RateLimit != nil {
+		clientOpts = append(clientOpts, client.WithRateLimit(tf2RateLimit(*confData.RateLimit)))
+	}
+	shopperID := os.Getenv("GODADDY_SHOPPER_ID")
+	if !(confData.ShopperID.IsUnknown() || confData.ShopperID.IsNull()) {
+		shopperID = confData.ShopperID.ValueString()
+	}
+	if shopperID != "" {
+		clientOpts = append(clientOpts, client.WithShopperID(shopperID))
+	}
+
+	apiClient, err := p.clientFactory(apiURL, apiKey, apiSecret, clientOpts...)
 	if err != nil {
 		resp.Diagnostics.AddError("failed to create API client", err.Error())
 		return
 	}
 
-	resp.ResourceData = client
+	cacheReads := true
+	if !(confData.CacheReads.IsUnknown() || confData.CacheReads.IsNull()) {
+		cacheReads = confData.CacheReads.ValueBool()
+	}
+	if cacheReads {
+		apiClient = client.NewCachingClient(apiClient)
+	}
+
+	pd := &providerData{client: apiClient}
+	if !(confData.BatchWindow.IsUnknown() || confData.BatchWindow.IsNull()) && confData.BatchWindow.ValueInt64() > 0 {
+		pd.coalescer = newWriteCoalescer(apiClient, p.locker,
+			time.Duration(confData.BatchWindow.ValueInt64())*time.Millisecond)
+	}
+
+	resp.ResourceData = pd
+	resp.DataSourceData = pd
+}
+
+// api URL: explicit from config or GODADDY_API_URL env var, else base URL
+// for environment from config or GODADDY_ENVIRONMENT env var
+func apiURLFromConfig(confData GoDaddyDNSProviderModel) (string, error) {
+	apiURL := os.Getenv("GODADDY_API_URL")
+	if !(confData.APIURL.IsUnknown() || confData.APIURL.IsNull()) {
+		apiURL = confData.APIURL.ValueString()
+	}
+	environment := os.Getenv("GODADDY_ENVIRONMENT")
+	if !(confData.Environment.IsUnknown() || confData.Environment.IsNull()) {
+		environment = confData.Environment.ValueString()
+		// explicit environment in config overrides url from env
+		if confData.APIURL.IsNull() {
+			apiURL = ""
+		}
+	}
+	return APIURL(apiURL, environment)
+}
+
+// APIURL returns explicit API URL if set (must be a valid http(s) one), else
+// base URL for environment ("production" if empty, or "ote")
+func APIURL(apiURL, environment string) (string, error) {
+	if apiURL != "" {
+		u, err := url.Parse(apiURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return "", fmt.Errorf("API URL %q is not a valid http(s) URL", apiURL)
+		}
+		return apiURL, nil
+	}
+	switch environment {
+	case "", ENV_PRODUCTION:
+		return GODADDY_API_URL, nil
+	case ENV_OTE:
+		return GODADDY_OTE_API_URL, nil
+	default:
+		return "", fmt.Errorf("unknown environment %q: must be %q or %q",
+			environment, ENV_PRODUCTION, ENV_OTE)
+	}
+}
+
+// rate limit settings from config, with defaults for unset values
+func tf2RateLimit(tfRL tfRateLimit) client.RateLimit {
+	rl := client.DefaultRateLimit
+	if !(tfRL.Algorithm.IsUnknown() || tfRL.Algorithm.IsNull()) {
+		rl.Algorithm = tfRL.Algorithm.ValueString()
+	}
+	if !(tfRL.Requests.IsUnknown() || tfRL.Requests.IsNull()) {
+		rl.Requests = int(tfRL.Requests.ValueInt64())
+	}
+	if !(tfRL.Period.IsUnknown() || tfRL.Period.IsNull()) {
+		rl.Period = time.Duration(tfRL.Period.ValueInt64()) * time.Second
+	}
+	if !(tfRL.Burst.IsUnknown() || tfRL.Burst.IsNull()) {
+		rl.Burst = int(tfRL.Burst.ValueInt64())
+	}
+	if !(tfRL.File.IsUnknown() || tfRL.File.IsNull()) {
+		rl.File = tfRL.File.ValueString()
+	}
+	return rl
+}
+
+// per-resource shopper ID override (if set) for client requests
+func shopperCtx(ctx context.Context, shopperID types.String) context.Context {
+	if shopperID.IsNull() || shopperID.IsUnknown() || shopperID.ValueString() == "" {
+		return ctx
+	}
+	ctx = tflog.SetField(ctx, "shopper_id", shopperID.ValueString())
+	return client.ContextWithShopperID(ctx, shopperID.ValueString())
 }
 
 func (p *GoDaddyDNSProvider) Resources(ctx context.Context) []func() resource.Resource {
 	return []func() resource.Resource{
-		RecordResourceFactory(&p.reqMutex),
+		RecordResourceFactory(p.locker),
+		RecordSetResourceFactory(p.locker),
+		ZoneResourceFactory(p.locker),
 	}
 }
 
 func (p *GoDaddyDNSProvider) DataSources(ctx context.Context) []func() datasource.DataSource {
-	return nil
-	// return []func() datasource.DataSource{
-	// 	NewEmptyDataSource,
-	// }
+	return []func() datasource.DataSource{
+		NewRecordsDataSource,
+	}
 }
 
 func New(version string, clientFactory APIClientFactory) func() provider.Provider {
@@ -126,7 +375,7 @@ func New(version string, clientFactory APIClientFactory) func() provider.Provide
 		return &GoDaddyDNSProvider{
 			version:       version,
 			clientFactory: clientFactory,
-			reqMutex:      sync.Mutex{},
+			locker:        newRecordLocker(),
 		}
 	}
 }
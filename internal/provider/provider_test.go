@@ -0,0 +1,148 @@
+package provider
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/veksh/terraform-provider-godaddy-dns/internal/client"
+)
+
+func TestAPIURLFromConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		conf    GoDaddyDNSProviderModel
+		envURL  string
+		envEnv  string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "default",
+			want: GODADDY_API_URL,
+		},
+		{
+			name: "ote from config",
+			conf: GoDaddyDNSProviderModel{Environment: types.StringValue("ote")},
+			want: GODADDY_OTE_API_URL,
+		},
+		{
+			name:   "ote from env",
+			envEnv: "ote",
+			want:   GODADDY_OTE_API_URL,
+		},
+		{
+			name:   "url from env",
+			envURL: "http://localhost:8080",
+			want:   "http://localhost:8080",
+		},
+		{
+			name:   "url from config overrides env",
+			conf:   GoDaddyDNSProviderModel{APIURL: types.StringValue("https://proxy.corp")},
+			envURL: "http://localhost:8080",
+			want:   "https://proxy.corp",
+		},
+		{
+			name:   "environment from config overrides url from env",
+			conf:   GoDaddyDNSProviderModel{Environment: types.StringValue("production")},
+			envURL: "http://localhost:8080",
+			want:   GODADDY_API_URL,
+		},
+		{
+			name:    "bad url",
+			conf:    GoDaddyDNSProviderModel{APIURL: types.StringValue("localhost:8080")},
+			wantErr: true,
+		},
+		{
+			name:    "bad environment in env",
+			envEnv:  "staging",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GODADDY_API_URL", tt.envURL)
+			t.Setenv("GODADDY_ENVIRONMENT", tt.envEnv)
+			// zero values are null
+			got, err := apiURLFromConfig(tt.conf)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error status: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("want %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestTF2RateLimit(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		conf tfRateLimit
+		want client.RateLimit
+	}{
+		{
+			name: "defaults",
+			want: client.DefaultRateLimit,
+		},
+		{
+			name: "slower window",
+			conf: tfRateLimit{Requests: types.Int64Value(30)},
+			want: client.RateLimit{Algorithm: client.RL_WINDOW, Requests: 30, Period: time.Minute},
+		},
+		{
+			name: "bucket",
+			conf: tfRateLimit{
+				Algorithm: types.StringValue("bucket"),
+				Requests:  types.Int64Value(120),
+				Period:    types.Int64Value(60),
+				Burst:     types.Int64Value(10),
+			},
+			want: client.RateLimit{Algorithm: client.RL_BUCKET, Requests: 120, Period: time.Minute, Burst: 10},
+		},
+		{
+			name: "file",
+			conf: tfRateLimit{
+				Algorithm: types.StringValue("file"),
+				File:      types.StringValue("/tmp/godaddy.ratelimit"),
+			},
+			want: client.RateLimit{Algorithm: client.RL_FILE, Requests: 60, Period: time.Minute, File: "/tmp/godaddy.ratelimit"},
+		},
+		{
+			name: "none",
+			conf: tfRateLimit{Algorithm: types.StringValue("none")},
+			want: client.RateLimit{Algorithm: client.RL_NONE, Requests: 60, Period: time.Minute},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tf2RateLimit(tt.conf); got != tt.want {
+				t.Errorf("want %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestAPIURL(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		apiURL, environment string
+		want                string
+		wantErr             bool
+	}{
+		{"", "", GODADDY_API_URL, false},
+		{"", ENV_PRODUCTION, GODADDY_API_URL, false},
+		{"", ENV_OTE, GODADDY_OTE_API_URL, false},
+		{"http://localhost:8080", ENV_OTE, "http://localhost:8080", false},
+		{"", "staging", "", true},
+		{"ftp://host", "", "", true},
+	}
+	for _, tt := range tests {
+		got, err := APIURL(tt.apiURL, tt.environment)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("APIURL(%q, %q): want %q (error %t), got %q (%v)",
+				tt.apiURL, tt.environment, tt.want, tt.wantErr, got, err)
+		}
+	}
+}
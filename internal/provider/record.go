@@ -3,16 +3,18 @@ package provider
 import (
 	"context"
 	"fmt"
+	"regexp"
 	"slices"
 	"strings"
-	"sync"
 
 	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64default"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
@@ -27,18 +29,29 @@ const IMPORT_SEP = ":"
 
 // Ensure provider defined types fully satisfy framework interfaces.
 var (
-	_ resource.Resource                = &RecordResource{}
-	_ resource.ResourceWithConfigure   = &RecordResource{}
-	_ resource.ResourceWithImportState = &RecordResource{}
+	_ resource.Resource                 = &RecordResource{}
+	_ resource.ResourceWithConfigure    = &RecordResource{}
+	_ resource.ResourceWithImportState  = &RecordResource{}
+	_ resource.ResourceWithUpgradeState = &RecordResource{}
+
+	_ resource.ResourceWithConfigValidators = &RecordResource{}
 )
 
 type tfDNSRecord struct {
-	Domain   types.String `tfsdk:"domain"`
-	Type     types.String `tfsdk:"type"`
-	Name     types.String `tfsdk:"name"`
-	Data     types.String `tfsdk:"data"`
-	TTL      types.Int64  `tfsdk:"ttl"`
-	Priority types.Int64  `tfsdk:"priority"`
+	ID       types.String    `tfsdk:"id"`
+	Domain   types.String    `tfsdk:"domain"`
+	Type     types.String    `tfsdk:"type"`
+	Name     types.String    `tfsdk:"name"`
+	Data     recordDataValue `tfsdk:"data"`
+	TTL      types.Int64     `tfsdk:"ttl"`
+	Priority types.Int64     `tfsdk:"priority"`
+	// SRV only
+	Service  types.String `tfsdk:"service"`
+	Protocol types.String `tfsdk:"protocol"`
+	Port     types.Int64  `tfsdk:"port"`
+	Weight   types.Int64  `tfsdk:"weight"`
+	// reseller sub-account
+	ShopperID types.String `tfsdk:"shopper_id"`
 }
 
 // add record fields to context; export TF_LOG=debug to view
@@ -48,7 +61,7 @@ func setLogCtx(ctx context.Context, tfRec tfDNSRecord, op string) context.Contex
 	ctx = tflog.SetField(ctx, "name", tfRec.Name.ValueString())
 	ctx = tflog.SetField(ctx, "data", tfRec.Data.ValueString())
 	ctx = tflog.SetField(ctx, "operation", op)
-	return ctx
+	return shopperCtx(ctx, tfRec.ShopperID)
 }
 
 // convert from terraform data model into api data model
@@ -60,21 +73,46 @@ func tf2model(tfData tfDNSRecord) (model.DNSDomain, model.DNSRecord) {
 			Data:     model.DNSRecordData(tfData.Data.ValueString()),
 			TTL:      model.DNSRecordTTL(tfData.TTL.ValueInt64()),
 			Priority: model.DNSRecordPrio(tfData.Priority.ValueInt64()),
+			Service:  model.DNSRecordSRVService(tfData.Service.ValueString()),
+			Protocol: model.DNSRecordSRVProto(tfData.Protocol.ValueString()),
+			Port:     model.DNSRecordSRVPort(tfData.Port.ValueInt64()),
+			Weight:   model.DNSRecordSRVWeight(tfData.Weight.ValueInt64()),
 		}
 }
 
+// record id from key fields, see recordID
+func tfRecordID(tfData tfDNSRecord) types.String {
+	return types.StringValue(recordID(tf2model(tfData)))
+}
+
 // RecordResource defines the implementation of GoDaddy DNS RR
 type RecordResource struct {
-	client   model.DNSApiClient
-	reqMutex *sync.Mutex
+	client model.DNSApiClient
+	locker *recordLocker
+	// nil if batching is disabled
+	coalescer *writeCoalescer
 }
 
-func RecordResourceFactory(m *sync.Mutex) func() resource.Resource {
+func RecordResourceFactory(l *recordLocker) func() resource.Resource {
 	return func() resource.Resource {
-		return &RecordResource{reqMutex: m}
+		return &RecordResource{locker: l}
 	}
 }
 
+// changes to multi-valued records could be batched (if enabled); batch
+// is applied under lock, so it must not be held while waiting
+func (r *RecordResource) batched(rType model.DNSRecordType) bool {
+	return r.coalescer != nil && !rType.IsSingleValue()
+}
+
+// serialize changes to records with the same type + name
+func (r *RecordResource) lock(tfRec tfDNSRecord) func() {
+	return r.locker.Lock(
+		model.DNSDomain(tfRec.Domain.ValueString()),
+		model.DNSRecordType(tfRec.Type.ValueString()),
+		model.DNSRecordName(tfRec.Name.ValueString()))
+}
+
 func (r *RecordResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
 	resp.TypeName = req.ProviderTypeName + "_record"
 }
@@ -82,7 +120,18 @@ func (r *RecordResource) Metadata(ctx context.Context, req resource.MetadataRequ
 func (r *RecordResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		MarkdownDescription: "DNS resource record represens a single RR in managed domain",
+		Version:             recordSchemaVersionNum(),
 		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Record identifier in import format: `<domain>:<type>:<name>:<data>`, " +
+					"`<domain>:SRV:<name>:<service>:<protocol>:<port>:<data>` for SRV, " +
+					"`<domain>:CAA:<name>:<tag> \"<value>\"` (without flags) for CAA, " +
+					"and just `<domain>:CNAME:<name>` for CNAME",
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					recordIDPlanModifier{},
+				},
+			},
 			"domain": schema.StringAttribute{
 				MarkdownDescription: "Name of main managed domain (top-level) for this RR",
 				Required:            true,
@@ -90,15 +139,19 @@ func (r *RecordResource) Schema(ctx context.Context, req resource.SchemaRequest,
 					stringplanmodifier.RequiresReplace(),
 				},
 			},
+			"shopper_id": schema.StringAttribute{
+				MarkdownDescription: "Shopper ID of reseller sub-account owning the domain, " +
+					"overrides provider `shopper_id`",
+				Optional: true,
+			},
 			"type": schema.StringAttribute{
 				MarkdownDescription: "Resource record type: A, CNAME etc",
 				Required:            true,
 				Validators: []validator.String{
-					// TODO: SRV management
 					// TODO: custom validator to require "priority" for type == MX
 					stringvalidator.Any(
 						// attempt to require priority only for MX: error message is not quite clear :)
-						stringvalidator.OneOf([]string{"A", "AAAA", "CNAME", "NS", "TXT"}...),
+						stringvalidator.OneOf([]string{"A", "AAAA", "CAA", "CNAME", "NS", "TXT"}...),
 						stringvalidator.All(
 							// mx requires priority
 							stringvalidator.OneOf([]string{"MX"}...),
@@ -106,6 +159,17 @@ func (r *RecordResource) Schema(ctx context.Context, req resource.SchemaRequest,
 								path.MatchRoot("priority"),
 							}...),
 						),
+						stringvalidator.All(
+							// srv requires all the key and value fields
+							stringvalidator.OneOf([]string{"SRV"}...),
+							stringvalidator.AlsoRequires(path.Expressions{
+								path.MatchRoot("priority"),
+								path.MatchRoot("weight"),
+								path.MatchRoot("service"),
+								path.MatchRoot("protocol"),
+								path.MatchRoot("port"),
+							}...),
+						),
 					),
 				},
 				PlanModifiers: []planmodifier.String{
@@ -120,8 +184,12 @@ func (r *RecordResource) Schema(ctx context.Context, req resource.SchemaRequest,
 				},
 			},
 			"data": schema.StringAttribute{
-				MarkdownDescription: "Record value returned for DNS query: target for CNAME, ip address for A etc",
-				Required:            true,
+				CustomType: recordDataType{},
+				MarkdownDescription: "Record value returned for DNS query: target host name for CNAME, MX, NS and SRV, " +
+					"IPv4 address for A, IPv6 for AAAA, `<flags> <tag> \"<value>\"` for CAA; " +
+					"TXT longer than 255 bytes must be split into quoted strings like `\"part 1\" \"part 2\"`; " +
+					"format is checked at plan time",
+				Required: true,
 			},
 			"ttl": schema.Int64Attribute{
 				MarkdownDescription: "Record time-to-live, >= 600s <= 604800s (1 week), default 3600 seconds (1 hour)",
@@ -134,33 +202,81 @@ func (r *RecordResource) Schema(ctx context.Context, req resource.SchemaRequest,
 				},
 			},
 			"priority": schema.Int64Attribute{
-				MarkdownDescription: "Record priority, required for MX (lower is higher)",
+				MarkdownDescription: "Record priority, required for MX and SRV (lower is higher)",
 				Optional:            true,
 				Validators: []validator.Int64{
 					int64validator.AtLeast(0),
 					int64validator.AtMost(1023),
 				},
 			},
+			"service": schema.StringAttribute{
+				MarkdownDescription: "Service name for SRV records, like `_ldap` or `_sip`",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(regexp.MustCompile(`^_[a-zA-Z0-9-]+$`),
+						"must start with `_` followed by letters, digits or `-`"),
+				},
+				// part of SRV key, like data
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"protocol": schema.StringAttribute{
+				MarkdownDescription: "Protocol for SRV records: `_tcp`, `_udp` or `_tls`",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf([]string{"_tcp", "_udp", "_tls"}...),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"port": schema.Int64Attribute{
+				MarkdownDescription: "Service port for SRV records, 1-65535",
+				Optional:            true,
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+					int64validator.AtMost(65535),
+				},
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.RequiresReplace(),
+				},
+			},
+			"weight": schema.Int64Attribute{
+				MarkdownDescription: "Relative weight for SRV records with the same priority (higher gets more load)",
+				Optional:            true,
+				Validators: []validator.Int64{
+					int64validator.AtLeast(0),
+					int64validator.AtMost(65535),
+				},
+			},
 		},
 	}
 }
 
+func (r *RecordResource) ConfigValidators(ctx context.Context) []resource.ConfigValidator {
+	return []resource.ConfigValidator{
+		recordDataValidator{},
+	}
+}
+
 func (r *RecordResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
 	// or it will panic on none
 	if req.ProviderData == nil {
 		return
 	}
 
-	client, ok := req.ProviderData.(model.DNSApiClient)
+	pd, ok := req.ProviderData.(*providerData)
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Resource Configure Type",
-			fmt.Sprintf("Internal error: expected *model.DNSApiClient, got: %T", req.ProviderData),
+			fmt.Sprintf("Internal error: expected *providerData, got: %T", req.ProviderData),
 		)
 		return
 	}
 
-	r.client = client
+	r.client = pd.client
+	r.coalescer = pd.coalescer
 }
 
 // create will complain (and fail with client error) if same record is already present
@@ -177,10 +293,21 @@ func (r *RecordResource) Create(ctx context.Context, req resource.CreateRequest,
 	ctx = setLogCtx(ctx, planData, "create")
 	tflog.Info(ctx, "create: start")
 	defer tflog.Info(ctx, "create: end")
-	r.reqMutex.Lock()
-	defer r.reqMutex.Unlock()
 
 	apiDomain, apiRecPlan := tf2model(planData)
+	if r.batched(apiRecPlan.Type) {
+		err := r.coalescer.Submit(ctx, apiDomain, recordChange{New: &apiRecPlan})
+		if err != nil {
+			addClientError(&resp.Diagnostics, "Unable to create record", err, recordErrorAttrs...)
+			return
+		}
+		planData.ID = tfRecordID(planData)
+		resp.Diagnostics.Append(resp.State.Set(ctx, &planData)...)
+		return
+	}
+
+	unlock := r.lock(planData)
+	defer unlock()
 	// "put"/"add" does not check prior state (terraform does not provide one for Create)
 	// and so will fail on uniqueness violation (e.g. if record already exists
 	// after external modification, or if it is the second CNAME RR etc)
@@ -191,11 +318,11 @@ func (r *RecordResource) Create(ctx context.Context, req resource.CreateRequest,
 	err := r.client.AddRecords(ctx, apiDomain, []model.DNSRecord{apiRecPlan})
 
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error",
-			fmt.Sprintf("Unable to create record: %s", err))
+		addClientError(&resp.Diagnostics, "Unable to create record", err, recordErrorAttrs...)
 		return
 	}
 
+	planData.ID = tfRecordID(planData)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &planData)...)
 }
 
@@ -209,15 +336,14 @@ func (r *RecordResource) Read(ctx context.Context, req resource.ReadRequest, res
 	ctx = setLogCtx(ctx, stateData, "read")
 	tflog.Info(ctx, "read: start")
 	defer tflog.Info(ctx, "read: end")
-	r.reqMutex.Lock()
-	defer r.reqMutex.Unlock()
+	unlock := r.lock(stateData)
+	defer unlock()
 
 	apiDomain, apiRecState := tf2model(stateData)
 
 	apiAllRecs, err := r.client.GetRecords(ctx, apiDomain, apiRecState.Type, apiRecState.Name)
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error",
-			fmt.Sprintf("Reading DNS records: query failed: %s", err))
+		addClientError(&resp.Diagnostics, "Reading DNS records: query failed", err)
 		return
 	}
 	numFound := 0
@@ -239,14 +365,19 @@ func (r *RecordResource) Read(ctx context.Context, req resource.ReadRequest, res
 			tflog.Debug(ctx, fmt.Sprintf("Got DNS record: %v", rec))
 			if rec.SameKey(apiRecState) {
 				tflog.Info(ctx, "matching DNS record found")
-				stateData.Data = types.StringValue(string(rec.Data))
+				// equivalent value from API is not a change
+				stateData.Data = newRecordData(keepStateData(rec, []model.DNSRecord{apiRecState}).Data)
 				stateData.TTL = types.Int64Value(int64(rec.TTL))
 				switch rec.Type {
 				case model.REC_MX:
 					stateData.Priority = types.Int64Value(int64(rec.Priority))
 				case model.REC_SRV:
 					stateData.Priority = types.Int64Value(int64(rec.Priority))
-					// TODO: weight
+					stateData.Weight = types.Int64Value(int64(rec.Weight))
+					// part of the key, so same as in state: refreshed just in case
+					stateData.Service = types.StringValue(string(rec.Service))
+					stateData.Protocol = types.StringValue(string(rec.Protocol))
+					stateData.Port = types.Int64Value(int64(rec.Port))
 				}
 				numFound += 1
 			}
@@ -265,6 +396,7 @@ func (r *RecordResource) Read(ctx context.Context, req resource.ReadRequest, res
 				"Duplicate resource instances present",
 				"Will use the last one")
 		}
+		stateData.ID = tfRecordID(stateData)
 		resp.Diagnostics.Append(resp.State.Set(ctx, &stateData)...)
 	}
 }
@@ -286,10 +418,27 @@ func (r *RecordResource) Update(ctx context.Context, req resource.UpdateRequest,
 	ctx = setLogCtx(ctx, planData, "update")
 	tflog.Info(ctx, "update: start")
 	defer tflog.Info(ctx, "update: end")
-	r.reqMutex.Lock()
-	defer r.reqMutex.Unlock()
 
 	apiDomain, apiRecPlan := tf2model(planData)
+	if r.batched(apiRecPlan.Type) {
+		var stateData tfDNSRecord
+		resp.Diagnostics.Append(req.State.Get(ctx, &stateData)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		_, apiRecState := tf2model(stateData)
+		err := r.coalescer.Submit(ctx, apiDomain, recordChange{Old: &apiRecState, New: &apiRecPlan})
+		if err != nil {
+			addClientError(&resp.Diagnostics, "Updating DNS failed", err, recordErrorAttrs...)
+			return
+		}
+		planData.ID = tfRecordID(planData)
+		resp.Diagnostics.Append(resp.State.Set(ctx, &planData)...)
+		return
+	}
+
+	unlock := r.lock(planData)
+	defer unlock()
 
 	var err error
 	if apiRecPlan.Type.IsSingleValue() {
@@ -310,8 +459,7 @@ func (r *RecordResource) Update(ctx context.Context, req resource.UpdateRequest,
 		}
 		apiUpdateRecs, err = r.apiRecsToKeep(ctx, stateData)
 		if err != nil && err != errRecordGone {
-			resp.Diagnostics.AddError("Client Error",
-				fmt.Sprintf("Getting DNS records to keep failed: %s", err))
+			addClientError(&resp.Diagnostics, "Getting DNS records to keep failed", err)
 			return
 		}
 		// lets try to detect the situation when old record is gone and new is present
@@ -323,12 +471,8 @@ func (r *RecordResource) Update(ctx context.Context, req resource.UpdateRequest,
 			oldGone = true
 		}
 		tflog.Info(ctx, fmt.Sprintf("Got %d records to keep", len(apiUpdateRecs)))
-		// and finally, add our record (TODO: SRV has more fields)
-		ourRec := model.DNSUpdateRecord{
-			Data:     apiRecPlan.Data,
-			TTL:      apiRecPlan.TTL,
-			Priority: apiRecPlan.Priority,
-		}
+		// and finally, add our record
+		ourRec := apiRecPlan.ToUpdate()
 		newPresent := false
 		if slices.Index(apiUpdateRecs, ourRec) >= 0 {
 			// still need to delete old value if not gone
@@ -346,11 +490,11 @@ func (r *RecordResource) Update(ctx context.Context, req resource.UpdateRequest,
 	}
 
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error",
-			fmt.Sprintf("Updating DNS failed: %s", err))
+		addClientError(&resp.Diagnostics, "Updating DNS failed", err, recordErrorAttrs...)
 		return
 	}
 
+	planData.ID = tfRecordID(planData)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &planData)...)
 }
 
@@ -365,17 +509,28 @@ func (r *RecordResource) Delete(ctx context.Context, req resource.DeleteRequest,
 	ctx = setLogCtx(ctx, stateData, "delete")
 	tflog.Info(ctx, "delete: start")
 	defer tflog.Info(ctx, "delete: end")
-	r.reqMutex.Lock()
-	defer r.reqMutex.Unlock()
 
 	apiDomain, apiRecState := tf2model(stateData)
+	if r.batched(apiRecState.Type) {
+		err := r.coalescer.Submit(ctx, apiDomain, recordChange{Old: &apiRecState})
+		if err != nil {
+			addClientError(&resp.Diagnostics, "Deleting DNS record failed", err)
+		}
+		return
+	}
+
+	unlock := r.lock(stateData)
+	defer unlock()
 
 	if apiRecState.Type.IsSingleValue() {
 		// for single-value types, delete is ok; multi-valued have to be replaced
 		err := r.client.DelRecords(ctx, apiDomain, apiRecState.Type, apiRecState.Name)
+		if isNotFound(err) {
+			tflog.Info(ctx, "DNS record already gone")
+			return
+		}
 		if err != nil {
-			resp.Diagnostics.AddError("Client Error",
-				fmt.Sprintf("Deleting DNS record failed: %s", err))
+			addClientError(&resp.Diagnostics, "Deleting DNS record failed", err)
 			return
 		}
 	} else {
@@ -391,8 +546,7 @@ func (r *RecordResource) Delete(ctx context.Context, req resource.DeleteRequest,
 				tflog.Info(ctx, "DNS record already gone")
 				return
 			} else {
-				resp.Diagnostics.AddError("Client Error",
-					fmt.Sprintf("Getting DNS records to keep failed: %s", err))
+				addClientError(&resp.Diagnostics, "Getting DNS records to keep failed", err)
 				return
 			}
 		}
@@ -403,14 +557,16 @@ func (r *RecordResource) Delete(ctx context.Context, req resource.DeleteRequest,
 			err = r.client.SetRecords(ctx, apiDomain, apiRecState.Type, apiRecState.Name, apiRecsToKeep)
 		}
 		if err != nil {
-			resp.Diagnostics.AddError("Client Error",
-				fmt.Sprintf("Replacing DNS records failed: %s", err))
+			addClientError(&resp.Diagnostics, "Replacing DNS records failed", err)
 			return
 		}
 	}
 }
 
 // terraform import godaddy-dns_record.new-cname domain:CNAME:_test:testing.com
+// terraform import godaddy-dns_record.new-srv domain:SRV:@:_sip:_tcp:5060:sip.domain.com
+// terraform import godaddy-dns_record.new-cname domain:CNAME:_test (if there is only one)
+// see importKey for the full format
 func (r *RecordResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
 	// resource.ImportStatePassthroughID(ctx, path.Root("data"), req, resp)
 
@@ -418,20 +574,78 @@ func (r *RecordResource) ImportState(ctx context.Context, req resource.ImportSta
 	// either as a separate structure in ReadRequest or as defaults: if only
 	// they were accessible, it would eliminate the need to pass anything here
 
-	idParts := strings.SplitN(req.ID, IMPORT_SEP, 4)
-
-	// mb check format and emptiness
-	if len(idParts) != 4 {
+	key, err := parseImportID(req.ID)
+	if err != nil {
 		resp.Diagnostics.AddError(
 			"Unexpected Import Identifier",
-			fmt.Sprintf("Expected import identifier format: domain:TYPE:name:data"+
-				"like mydom.com:CNAME:www.subdom:www.other.com. Got: %q", req.ID),
+			fmt.Sprintf("Expected import identifier format: domain:TYPE:name[:keys][:data] "+
+				"like mydom.com:CNAME:www.subdom:www.other.com or mydom.com:SRV:@:_sip:_tcp:5060:sip.mydom.com; "+
+				"got %q:\n%s", req.ID, err),
 		)
 		return
 	}
 
-	for i, f := range []string{"domain", "type", "name", "data"} {
-		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root(f), idParts[i])...)
+	rec := key.Record
+	if rec.Data == "" || key.NoFlags {
+		var ok bool
+		if rec, ok = r.importResolve(ctx, key, resp); !ok {
+			return
+		}
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), recordID(key.Domain, rec))...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("domain"), string(key.Domain))...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("type"), string(rec.Type))...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("name"), string(rec.Name))...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("data"), string(rec.Data))...)
+	switch rec.Type {
+	case model.REC_SRV:
+		// SRV records are keyed by service, protocol and port too
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("service"), string(rec.Service))...)
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("protocol"), string(rec.Protocol))...)
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("port"), int64(rec.Port))...)
+	case model.REC_MX:
+		// not a part of the key: will be read anyway
+		if key.HasPriority {
+			resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("priority"), int64(rec.Priority))...)
+		}
+	}
+}
+
+// import without data (or CAA without flags): take it (and SRV keys, if not
+// set) from API if there is only one matching record of type + name (always
+// the case for CNAME)
+func (r *RecordResource) importResolve(ctx context.Context, key importKey, resp *resource.ImportStateResponse) (model.DNSRecord, bool) {
+	rType, rName := key.Record.Type, key.Record.Name
+	apiAllRecs, err := r.client.GetRecords(ctx, key.Domain, rType, rName)
+	if err != nil {
+		addClientError(&resp.Diagnostics, "Import: query for record data failed", err)
+		return model.DNSRecord{}, false
+	}
+	apiRecs := slices.DeleteFunc(apiAllRecs, func(rec model.DNSRecord) bool {
+		return !key.matches(rec)
+	})
+	switch len(apiRecs) {
+	case 0:
+		resp.Diagnostics.AddError(
+			"Record Not Found",
+			fmt.Sprintf("There are no matching %s records named %q in %s", rType, rName, key.Domain),
+		)
+		return model.DNSRecord{}, false
+	case 1:
+		return apiRecs[0], true
+	default:
+		ids := make([]string, 0, len(apiRecs))
+		for _, rec := range apiRecs {
+			ids = append(ids, "  "+formatImportID(key.Domain, rec))
+		}
+		resp.Diagnostics.AddError(
+			"Ambiguous Import Identifier",
+			fmt.Sprintf("There are %d %s records named %q in %s, use full import "+
+				"identifier to select one of them:\n%s",
+				len(apiRecs), rType, rName, key.Domain, strings.Join(ids, "\n")),
+		)
+		return model.DNSRecord{}, false
 	}
 }
 
@@ -481,3 +695,34 @@ func (r *RecordResource) apiRecsToKeep(ctx context.Context, stateData tfDNSRecor
 	}
 	return res, nil
 }
+
+// id is known at plan time if all the key fields are known, so that key
+// changes are shown in plan instead of "known after apply"
+type recordIDPlanModifier struct{}
+
+func (m recordIDPlanModifier) Description(ctx context.Context) string {
+	return "id is computed from domain, type, name, data and SRV key fields"
+}
+
+func (m recordIDPlanModifier) MarkdownDescription(ctx context.Context) string {
+	return m.Description(ctx)
+}
+
+func (m recordIDPlanModifier) PlanModifyString(ctx context.Context, req planmodifier.StringRequest, resp *planmodifier.StringResponse) {
+	// destroy
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+	var planData tfDNSRecord
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &planData)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	for _, v := range []attr.Value{planData.Domain, planData.Type, planData.Name,
+		planData.Data, planData.Service, planData.Protocol, planData.Port} {
+		if v.IsUnknown() {
+			return
+		}
+	}
+	resp.PlanValue = tfRecordID(planData)
+}
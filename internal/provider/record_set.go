@@ -0,0 +1,442 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64default"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/veksh/terraform-provider-godaddy-dns/internal/model"
+)
+
+var (
+	_ resource.Resource                   = &RecordSetResource{}
+	_ resource.ResourceWithConfigure      = &RecordSetResource{}
+	_ resource.ResourceWithImportState    = &RecordSetResource{}
+	_ resource.ResourceWithValidateConfig = &RecordSetResource{}
+)
+
+type tfDNSRecordSet struct {
+	Domain    types.String          `tfsdk:"domain"`
+	Type      types.String          `tfsdk:"type"`
+	Name      types.String          `tfsdk:"name"`
+	Records   []tfDNSRecordSetValue `tfsdk:"records"`
+	Exclusive types.Bool            `tfsdk:"exclusive"`
+	ShopperID types.String          `tfsdk:"shopper_id"`
+}
+
+type tfDNSRecordSetValue struct {
+	Data     recordDataValue `tfsdk:"data"`
+	TTL      types.Int64     `tfsdk:"ttl"`
+	Priority types.Int64     `tfsdk:"priority"`
+}
+
+// add record set fields to context
+func setLogCtxSet(ctx context.Context, tfSet tfDNSRecordSet, op string) context.Context {
+	ctx = tflog.SetField(ctx, "domain", tfSet.Domain.ValueString())
+	ctx = tflog.SetField(ctx, "type", tfSet.Type.ValueString())
+	ctx = tflog.SetField(ctx, "name", tfSet.Name.ValueString())
+	ctx = tflog.SetField(ctx, "exclusive", tfSet.Exclusive.ValueBool())
+	ctx = tflog.SetField(ctx, "operation", op)
+	return shopperCtx(ctx, tfSet.ShopperID)
+}
+
+// convert from terraform data model into api data model
+func tfSet2model(tfData tfDNSRecordSet) (model.DNSDomain, []model.DNSRecord) {
+	res := make([]model.DNSRecord, 0, len(tfData.Records))
+	for _, v := range tfData.Records {
+		res = append(res, model.DNSRecord{
+			Name:     model.DNSRecordName(tfData.Name.ValueString()),
+			Type:     model.DNSRecordType(tfData.Type.ValueString()),
+			Data:     model.DNSRecordData(v.Data.ValueString()),
+			TTL:      model.DNSRecordTTL(v.TTL.ValueInt64()),
+			Priority: model.DNSRecordPrio(v.Priority.ValueInt64()),
+		})
+	}
+	return model.DNSDomain(tfData.Domain.ValueString()), res
+}
+
+// convert api record into set value; priority is only meaningful for MX
+func model2tfSetValue(rec model.DNSRecord) tfDNSRecordSetValue {
+	val := tfDNSRecordSetValue{
+		Data:     newRecordData(rec.Data),
+		TTL:      types.Int64Value(int64(rec.TTL)),
+		Priority: types.Int64Null(),
+	}
+	if rec.Type == model.REC_MX {
+		val.Priority = types.Int64Value(int64(rec.Priority))
+	}
+	return val
+}
+
+// RecordSetResource manages all the values of one type + name at once
+type RecordSetResource struct {
+	client model.DNSApiClient
+	locker *recordLocker
+}
+
+func RecordSetResourceFactory(l *recordLocker) func() resource.Resource {
+	return func() resource.Resource {
+		return &RecordSetResource{locker: l}
+	}
+}
+
+// serialize changes to records with the same type + name
+func (r *RecordSetResource) lock(tfSet tfDNSRecordSet) func() {
+	return r.locker.Lock(
+		model.DNSDomain(tfSet.Domain.ValueString()),
+		model.DNSRecordType(tfSet.Type.ValueString()),
+		model.DNSRecordName(tfSet.Name.ValueString()))
+}
+
+func (r *RecordSetResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_record_set"
+}
+
+func (r *RecordSetResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "DNS record set represents all the values of one type for one name in managed domain",
+		Attributes: map[string]schema.Attribute{
+			"domain": schema.StringAttribute{
+				MarkdownDescription: "Name of main managed domain (top-level) for this record set",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"shopper_id": schema.StringAttribute{
+				MarkdownDescription: "Shopper ID of reseller sub-account owning the domain, " +
+					"overrides provider `shopper_id`",
+				Optional: true,
+			},
+			"type": schema.StringAttribute{
+				MarkdownDescription: "Resource record type: A, MX, TXT etc (SRV is not supported)",
+				Required:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf([]string{"A", "AAAA", "CAA", "CNAME", "MX", "NS", "TXT"}...),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"name": schema.StringAttribute{
+				MarkdownDescription: "Record name (part of FQN), may include `.` for records in sub-domains or be `@` for top-level records",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"records": schema.SetNestedAttribute{
+				MarkdownDescription: "Record values",
+				Required:            true,
+				Validators: []validator.Set{
+					setvalidator.SizeAtLeast(1),
+				},
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"data": schema.StringAttribute{
+							CustomType:          recordDataType{},
+							MarkdownDescription: "Record value returned for DNS query: target for CNAME, ip address for A etc",
+							Required:            true,
+						},
+						"ttl": schema.Int64Attribute{
+							MarkdownDescription: "Record time-to-live, >= 600s <= 604800s (1 week), default 3600 seconds (1 hour)",
+							Optional:            true,
+							Computed:            true,
+							Default:             int64default.StaticInt64(3600),
+							Validators: []validator.Int64{
+								int64validator.AtLeast(600),
+								int64validator.AtMost(604800),
+							},
+						},
+						"priority": schema.Int64Attribute{
+							MarkdownDescription: "Record priority, required for MX and not allowed for other types (lower is higher)",
+							Optional:            true,
+							Validators: []validator.Int64{
+								int64validator.AtLeast(0),
+								int64validator.AtMost(1023),
+							},
+						},
+					},
+				},
+			},
+			"exclusive": schema.BoolAttribute{
+				MarkdownDescription: "Remove values not listed in `records` (default `false`: keep them intact)",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(false),
+			},
+		},
+	}
+}
+
+// per-value checks that depend on record type
+func (r *RecordSetResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var recType types.String
+	var recVals types.Set
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("type"), &recType)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("records"), &recVals)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if recType.IsNull() || recType.IsUnknown() || recVals.IsNull() || recVals.IsUnknown() {
+		return
+	}
+	var vals []tfDNSRecordSetValue
+	resp.Diagnostics.Append(recVals.ElementsAs(ctx, &vals, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	rType := model.DNSRecordType(recType.ValueString())
+	if rType.IsSingleValue() && len(vals) > 1 {
+		resp.Diagnostics.AddAttributeError(path.Root("records"),
+			"Too many values",
+			fmt.Sprintf("There could be only one %s record with a given name", rType))
+	}
+	for _, v := range vals {
+		if rType == model.REC_MX && v.Priority.IsNull() {
+			resp.Diagnostics.AddAttributeError(path.Root("records"),
+				"Missing priority",
+				fmt.Sprintf("Priority is required for MX, missing for %q", v.Data.ValueString()))
+		}
+		// would be lost on read, resulting in diff on every plan
+		if rType != model.REC_MX && !v.Priority.IsNull() {
+			resp.Diagnostics.AddAttributeError(path.Root("records"),
+				"Unexpected priority",
+				fmt.Sprintf("Priority is only supported for MX, not %s, set for %q", rType, v.Data.ValueString()))
+		}
+		if !(v.Data.IsNull() || v.Data.IsUnknown()) {
+			if err := model.ValidateData(rType, model.DNSRecordData(v.Data.ValueString())); err != nil {
+				resp.Diagnostics.AddAttributeError(path.Root("records"),
+					fmt.Sprintf("Invalid %s record data", rType), err.Error())
+			}
+		}
+	}
+}
+
+func (r *RecordSetResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	pd, ok := req.ProviderData.(*providerData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Internal error: expected *providerData, got: %T", req.ProviderData),
+		)
+		return
+	}
+
+	r.client = pd.client
+}
+
+// exclusive: just one PUT with planned values
+// non-exclusive: read current values, keep the unknown ones, PUT them + planned
+func (r *RecordSetResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var planData tfDNSRecordSet
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &planData)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx = setLogCtxSet(ctx, planData, "create")
+	tflog.Info(ctx, "create: start")
+	defer tflog.Info(ctx, "create: end")
+	unlock := r.lock(planData)
+	defer unlock()
+
+	err := r.setValues(ctx, planData, nil)
+	if err != nil {
+		addClientError(&resp.Diagnostics, "Unable to create record set", err)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &planData)...)
+}
+
+// exclusive: all the values for type + name
+// non-exclusive: only values matching the ones in state
+func (r *RecordSetResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var stateData tfDNSRecordSet
+	resp.Diagnostics.Append(req.State.Get(ctx, &stateData)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx = setLogCtxSet(ctx, stateData, "read")
+	tflog.Info(ctx, "read: start")
+	defer tflog.Info(ctx, "read: end")
+	unlock := r.lock(stateData)
+	defer unlock()
+
+	apiDomain, apiRecsState := tfSet2model(stateData)
+	apiAllRecs, err := r.client.GetRecords(ctx, apiDomain,
+		model.DNSRecordType(stateData.Type.ValueString()),
+		model.DNSRecordName(stateData.Name.ValueString()))
+	if err != nil {
+		addClientError(&resp.Diagnostics, "Reading DNS records: query failed", err)
+		return
+	}
+	tflog.Info(ctx, fmt.Sprintf("Reading DNS record set: got %d answers", len(apiAllRecs)))
+
+	vals := []tfDNSRecordSetValue{}
+	for _, rec := range apiAllRecs {
+		if stateData.Exclusive.ValueBool() || matchesAny(rec, apiRecsState) {
+			vals = append(vals, model2tfSetValue(keepStateData(rec, apiRecsState)))
+		}
+	}
+	if len(vals) == 0 {
+		tflog.Info(ctx, "Record set is currently absent")
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	stateData.Records = vals
+	resp.Diagnostics.Append(resp.State.Set(ctx, &stateData)...)
+}
+
+func (r *RecordSetResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var planData, stateData tfDNSRecordSet
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &planData)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &stateData)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx = setLogCtxSet(ctx, planData, "update")
+	tflog.Info(ctx, "update: start")
+	defer tflog.Info(ctx, "update: end")
+	unlock := r.lock(planData)
+	defer unlock()
+
+	// previous values are ours to replace, so do not keep them
+	_, apiRecsState := tfSet2model(stateData)
+	err := r.setValues(ctx, planData, apiRecsState)
+	if err != nil {
+		addClientError(&resp.Diagnostics, "Updating DNS record set failed", err)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &planData)...)
+}
+
+func (r *RecordSetResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var stateData tfDNSRecordSet
+	resp.Diagnostics.Append(req.State.Get(ctx, &stateData)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx = setLogCtxSet(ctx, stateData, "delete")
+	tflog.Info(ctx, "delete: start")
+	defer tflog.Info(ctx, "delete: end")
+	unlock := r.lock(stateData)
+	defer unlock()
+
+	apiDomain, apiRecsState := tfSet2model(stateData)
+	rType := model.DNSRecordType(stateData.Type.ValueString())
+	rName := model.DNSRecordName(stateData.Name.ValueString())
+
+	var err error
+	apiRecsToKeep := []model.DNSUpdateRecord{}
+	if !stateData.Exclusive.ValueBool() {
+		apiRecsToKeep, err = r.apiRecsToKeep(ctx, apiDomain, rType, rName, apiRecsState)
+		if err != nil {
+			addClientError(&resp.Diagnostics, "Getting DNS records to keep failed", err)
+			return
+		}
+	}
+	tflog.Info(ctx, fmt.Sprintf("Got %d records to keep", len(apiRecsToKeep)))
+	if len(apiRecsToKeep) == 0 {
+		err = r.client.DelRecords(ctx, apiDomain, rType, rName)
+	} else {
+		err = r.client.SetRecords(ctx, apiDomain, rType, rName, apiRecsToKeep)
+	}
+	if err != nil {
+		addClientError(&resp.Diagnostics, "Deleting DNS record set failed", err)
+		return
+	}
+}
+
+// terraform import godaddy-dns_record_set.mx domain:MX:@
+// imported set takes ownership of all the values, so it is exclusive
+func (r *RecordSetResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	idParts := strings.Split(req.ID, IMPORT_SEP)
+
+	if len(idParts) != 3 || idParts[0] == "" || idParts[1] == "" || idParts[2] == "" {
+		resp.Diagnostics.AddError(
+			"Unexpected Import Identifier",
+			fmt.Sprintf("Expected import identifier format: domain:TYPE:name "+
+				"like mydom.com:MX:@. Got: %q", req.ID),
+		)
+		return
+	}
+
+	for i, f := range []string{"domain", "type", "name"} {
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root(f), idParts[i])...)
+	}
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("exclusive"), true)...)
+}
+
+// replace values for type + name with the planned ones; if set is not exclusive,
+// keep current values except for the planned and the ones in toReplace
+func (r *RecordSetResource) setValues(ctx context.Context, planData tfDNSRecordSet, toReplace []model.DNSRecord) error {
+	apiDomain, apiRecsPlan := tfSet2model(planData)
+	rType := model.DNSRecordType(planData.Type.ValueString())
+	rName := model.DNSRecordName(planData.Name.ValueString())
+
+	apiUpdateRecs := []model.DNSUpdateRecord{}
+	if !planData.Exclusive.ValueBool() {
+		var err error
+		apiUpdateRecs, err = r.apiRecsToKeep(ctx, apiDomain, rType, rName,
+			append(toReplace, apiRecsPlan...))
+		if err != nil {
+			return err
+		}
+		tflog.Info(ctx, fmt.Sprintf("Got %d records to keep", len(apiUpdateRecs)))
+	}
+	for _, rec := range apiRecsPlan {
+		apiUpdateRecs = append(apiUpdateRecs, rec.ToUpdate())
+	}
+	return r.client.SetRecords(ctx, apiDomain, rType, rName, apiUpdateRecs)
+}
+
+// get all records for type + name except the ones matching any of "ours",
+// converted to update format
+func (r *RecordSetResource) apiRecsToKeep(ctx context.Context, apiDomain model.DNSDomain,
+	rType model.DNSRecordType, rName model.DNSRecordName, ours []model.DNSRecord) ([]model.DNSUpdateRecord, error) {
+	res := []model.DNSUpdateRecord{}
+	apiAllRecs, err := r.client.GetRecords(ctx, apiDomain, rType, rName)
+	if err != nil {
+		return res, err
+	}
+	for _, rec := range apiAllRecs {
+		if !matchesAny(rec, ours) {
+			res = append(res, rec.ToUpdate())
+		}
+	}
+	return res, nil
+}
+
+// true if record has the same key as any of the given
+func matchesAny(rec model.DNSRecord, recs []model.DNSRecord) bool {
+	for _, r := range recs {
+		if rec.SameKey(r) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,131 @@
+package provider
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/veksh/terraform-provider-godaddy-dns/internal/model"
+)
+
+// create terraform config for TXT record set with given values
+func recordSetConfig(exclusive bool, values []model.DNSRecordData) string {
+	quoted := make([]string, 0, len(values))
+	for _, v := range values {
+		quoted = append(quoted, fmt.Sprintf("{ data = %q }", v))
+	}
+	return testProviderConfig + fmt.Sprintf(`
+	resource "godaddy-dns_record_set" "test-txt" {
+	  domain    = "%s"
+	  type      = "TXT"
+	  name      = "test-txt-set._test"
+	  exclusive = %t
+	  records   = [%s]
+	}`, TEST_DOMAIN, exclusive, strings.Join(quoted, ", "))
+}
+
+// exclusive set: create and update are single PUTs, values not in config
+// are removed, delete removes everything
+func TestUnitRecordSetExclusive(t *testing.T) {
+	mType, mName := model.REC_TXT, model.DNSRecordName("test-txt-set._test")
+	tfResName := "godaddy-dns_record_set.test-txt"
+	mkRecs := func(vals ...model.DNSRecordData) []model.DNSRecord {
+		res := []model.DNSRecord{}
+		for _, v := range vals {
+			res = append(res, model.DNSRecord{Type: mType, Name: mName, Data: v, TTL: 3600})
+		}
+		return res
+	}
+	mkUpds := func(vals ...model.DNSRecordData) []model.DNSUpdateRecord {
+		res := []model.DNSUpdateRecord{}
+		for _, v := range vals {
+			res = append(res, model.DNSUpdateRecord{Data: v, TTL: 3600})
+		}
+		return res
+	}
+
+	// create: no read required
+	mClientAdd := model.NewMockDNSApiClient(t)
+	mClientAdd.EXPECT().SetRecords(mCtx, mDom, mType, mName, updRecsInAnyOrder(mkUpds("one", "two"))).Return(nil).Once()
+	mClientAdd.EXPECT().GetRecords(mCtx, mDom, mType, mName).Return(mkRecs("one", "two"), nil)
+
+	// refresh finds foreign value, update replaces all, then delete
+	mClientUpd := model.NewMockDNSApiClient(t)
+	mClientUpd.EXPECT().GetRecords(mCtx, mDom, mType, mName).Return(mkRecs("one", "two", "foreign"), nil).Once()
+	mClientUpd.EXPECT().SetRecords(mCtx, mDom, mType, mName, updRecsInAnyOrder(mkUpds("one", "three"))).Return(nil).Once()
+	mClientUpd.EXPECT().GetRecords(mCtx, mDom, mType, mName).Return(mkRecs("one", "three"), nil)
+	mClientUpd.EXPECT().DelRecords(mCtx, mDom, mType, mName).Return(nil).Once()
+
+	resource.UnitTest(t, resource.TestCase{
+		Steps: []resource.TestStep{
+			{
+				ProtoV6ProviderFactories: mockClientProviderFactory(mClientAdd),
+				Config:                   recordSetConfig(true, []model.DNSRecordData{"one", "two"}),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(tfResName, "records.#", "2"),
+				),
+			},
+			{
+				ProtoV6ProviderFactories: mockClientProviderFactory(mClientUpd),
+				Config:                   recordSetConfig(true, []model.DNSRecordData{"one", "three"}),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(tfResName, "records.#", "2"),
+				),
+			},
+		},
+	})
+}
+
+// non-exclusive set: values not in config are preserved on all operations
+func TestUnitRecordSetNonExclusive(t *testing.T) {
+	mType, mName := model.REC_TXT, model.DNSRecordName("test-txt-set._test")
+	tfResName := "godaddy-dns_record_set.test-txt"
+	foreign := model.DNSRecord{Type: mType, Name: mName, Data: "foreign", TTL: 600}
+	mkRecs := func(vals ...model.DNSRecordData) []model.DNSRecord {
+		res := []model.DNSRecord{foreign}
+		for _, v := range vals {
+			res = append(res, model.DNSRecord{Type: mType, Name: mName, Data: v, TTL: 3600})
+		}
+		return res
+	}
+	mkUpds := func(vals ...model.DNSRecordData) []model.DNSUpdateRecord {
+		res := []model.DNSUpdateRecord{foreign.ToUpdate()}
+		for _, v := range vals {
+			res = append(res, model.DNSUpdateRecord{Data: v, TTL: 3600})
+		}
+		return res
+	}
+
+	// create: read foreign, put it back with ours
+	mClientAdd := model.NewMockDNSApiClient(t)
+	mClientAdd.EXPECT().GetRecords(mCtx, mDom, mType, mName).Return(mkRecs(), nil).Once()
+	mClientAdd.EXPECT().SetRecords(mCtx, mDom, mType, mName, updRecsInAnyOrder(mkUpds("one", "two"))).Return(nil).Once()
+	mClientAdd.EXPECT().GetRecords(mCtx, mDom, mType, mName).Return(mkRecs("one", "two"), nil)
+
+	// update: replace "two" with "three", keep foreign; delete: keep only foreign
+	mClientUpd := model.NewMockDNSApiClient(t)
+	mClientUpd.EXPECT().GetRecords(mCtx, mDom, mType, mName).Return(mkRecs("one", "two"), nil).Twice()
+	mClientUpd.EXPECT().SetRecords(mCtx, mDom, mType, mName, updRecsInAnyOrder(mkUpds("one", "three"))).Return(nil).Once()
+	mClientUpd.EXPECT().GetRecords(mCtx, mDom, mType, mName).Return(mkRecs("one", "three"), nil)
+	mClientUpd.EXPECT().SetRecords(mCtx, mDom, mType, mName, mkUpds()).Return(nil).Once()
+
+	resource.UnitTest(t, resource.TestCase{
+		Steps: []resource.TestStep{
+			{
+				ProtoV6ProviderFactories: mockClientProviderFactory(mClientAdd),
+				Config:                   recordSetConfig(false, []model.DNSRecordData{"one", "two"}),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(tfResName, "records.#", "2"),
+				),
+			},
+			{
+				ProtoV6ProviderFactories: mockClientProviderFactory(mClientUpd),
+				Config:                   recordSetConfig(false, []model.DNSRecordData{"one", "three"}),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(tfResName, "records.#", "2"),
+				),
+			},
+		},
+	})
+}
@@ -17,9 +17,17 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"regexp"
 	"slices"
+	"sync"
 	"testing"
 
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
 	"github.com/hashicorp/terraform-plugin-testing/terraform"
 	"github.com/stretchr/testify/mock"
@@ -227,6 +235,165 @@ func TestUnitMXLifecycle(t *testing.T) {
 	})
 }
 
+// SRV resource lifecycle with another SRV for the same name (and even the
+// same service) that must be kept intact
+func TestUnitSRVLifecycle(t *testing.T) {
+	mType, mName := model.REC_SRV, model.DNSRecordName("test-srv._test")
+	tfResName := "godaddy-dns_record.test-srv"
+	mData := model.DNSRecordData("sip1.test.com")
+	mDataOther := model.DNSRecordData("sip2.test.com")
+	mRecs := []model.DNSRecord{
+		{
+			Name:     mName,
+			Type:     mType,
+			Data:     mDataOther,
+			TTL:      3600,
+			Priority: 10,
+			Weight:   10,
+			Service:  "_sip",
+			Protocol: "_tcp",
+			Port:     5060,
+		}, {
+			Name:     mName,
+			Type:     mType,
+			Data:     mData,
+			TTL:      3600,
+			Priority: 20,
+			Weight:   5,
+			Service:  "_sip",
+			Protocol: "_tcp",
+			Port:     5060,
+		},
+	}
+	mUpdates := []model.DNSUpdateRecord{mRecs[0].ToUpdate(), mRecs[1].ToUpdate()}
+	mUpdates[1].Weight = 50
+
+	// add record, read it back
+	mockClientAdd := model.NewMockDNSApiClient(t)
+	mockClientAdd.EXPECT().AddRecords(mCtx, mDom, mRecs[1:2]).Return(nil).Once()
+	mockClientAdd.EXPECT().GetRecords(mCtx, mDom, mType, mName).Return(mRecs, nil)
+
+	// read state
+	mockClientImp := model.NewMockDNSApiClient(t)
+	mockClientImp.EXPECT().GetRecords(mCtx, mDom, mType, mName).Return(mRecs, nil)
+
+	// read, update weight, then delete
+	mockClientUpd := model.NewMockDNSApiClient(t)
+	mRecsUpdated := slices.Clone(mRecs)
+	mRecsUpdated[1].Weight = 50
+	// read + update
+	mockClientUpd.EXPECT().GetRecords(mCtx, mDom, mType, mName).Return(mRecs, nil).Twice()
+	mockClientUpd.EXPECT().SetRecords(mCtx, mDom, mType, mName, mUpdates).Return(nil).Once()
+	// cleanup: delete by setting sibling back
+	mockClientUpd.EXPECT().GetRecords(mCtx, mDom, mType, mName).Return(mRecsUpdated, nil).Twice()
+	mockClientUpd.EXPECT().SetRecords(mCtx, mDom, mType, mName, mUpdates[:1]).Return(nil).Once()
+
+	resource.UnitTest(t, resource.TestCase{
+		Steps: []resource.TestStep{
+			// create, read back
+			{
+				ProtoV6ProviderFactories: mockClientProviderFactory(mockClientAdd),
+				Config:                   srvResourceConfig(mData, 20, 5),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(tfResName, "weight", "5"),
+					resource.TestCheckResourceAttr(tfResName, "port", "5060"),
+				),
+			},
+			// import by full SRV key, should produce no plan
+			{
+				ProtoV6ProviderFactories: mockClientProviderFactory(mockClientImp),
+				ResourceName:             tfResName,
+				ImportState:              true,
+				ImportStateVerify:        true,
+				ImportStateIdFunc: func(s *terraform.State) (string, error) {
+					attrs := s.Modules[0].Resources[tfResName].Primary.Attributes
+					return fmt.Sprintf("%s:%s:%s:%s:%s:%s:%s",
+						attrs["domain"], attrs["type"], attrs["name"],
+						attrs["service"], attrs["protocol"], attrs["port"], attrs["data"]), nil
+				},
+				ImportStateVerifyIdentifierAttribute: "name",
+			},
+			// update weight, read back, delete keeping the sibling
+			{
+				ProtoV6ProviderFactories: mockClientProviderFactory(mockClientUpd),
+				Config:                   srvResourceConfig(mData, 20, 50),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(tfResName, "weight", "50"),
+				),
+			},
+		},
+	})
+}
+
+// CAA resource lifecycle: changing flags updates the record in place
+// while another CAA for the same name (different tag) is kept intact
+func TestUnitCAALifecycle(t *testing.T) {
+	mType, mName := model.REC_CAA, model.DNSRecordName("test-caa._test")
+	tfResName := "godaddy-dns_record.test-caa"
+	mData := model.DNSRecordData(`0 issue "letsencrypt.org"`)
+	mDataChanged := model.DNSRecordData(`128 issue "letsencrypt.org"`)
+	mDataOther := model.DNSRecordData(`0 issuewild ";"`)
+	mRecs := []model.DNSRecord{
+		{Name: mName, Type: mType, Data: mDataOther, TTL: 3600},
+		{Name: mName, Type: mType, Data: mData, TTL: 3600},
+	}
+	mUpdates := []model.DNSUpdateRecord{
+		{Data: mDataOther, TTL: 3600},
+		{Data: mDataChanged, TTL: 3600},
+	}
+
+	// add record, read it back
+	mockClientAdd := model.NewMockDNSApiClient(t)
+	mockClientAdd.EXPECT().AddRecords(mCtx, mDom, mRecs[1:2]).Return(nil).Once()
+	mockClientAdd.EXPECT().GetRecords(mCtx, mDom, mType, mName).Return(mRecs, nil)
+
+	// read, update, then delete
+	mockClientUpd := model.NewMockDNSApiClient(t)
+	mRecsUpdated := slices.Clone(mRecs)
+	mRecsUpdated[1].Data = mDataChanged
+	// read + update
+	mockClientUpd.EXPECT().GetRecords(mCtx, mDom, mType, mName).Return(mRecs, nil).Twice()
+	mockClientUpd.EXPECT().SetRecords(mCtx, mDom, mType, mName, mUpdates).Return(nil).Once()
+	// cleanup: delete by setting it back
+	mockClientUpd.EXPECT().GetRecords(mCtx, mDom, mType, mName).Return(mRecsUpdated, nil).Twice()
+	mockClientUpd.EXPECT().SetRecords(mCtx, mDom, mType, mName, mUpdates[:1]).Return(nil).Once()
+
+	resource.UnitTest(t, resource.TestCase{
+		Steps: []resource.TestStep{
+			// create, read back
+			{
+				ProtoV6ProviderFactories: mockClientProviderFactory(mockClientAdd),
+				Config:                   caaResourceConfig(mData),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(tfResName, "data", string(mData)),
+				),
+			},
+			// read back, update flags, clean up
+			{
+				ProtoV6ProviderFactories: mockClientProviderFactory(mockClientUpd),
+				Config:                   caaResourceConfig(mDataChanged),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(tfResName, "data", string(mDataChanged)),
+				),
+			},
+		},
+	})
+}
+
+// malformed CAA data must be rejected at plan time, before any API calls
+func TestUnitCAAInvalidData(t *testing.T) {
+	mClient := model.NewMockDNSApiClient(t)
+	resource.UnitTest(t, resource.TestCase{
+		Steps: []resource.TestStep{
+			{
+				ProtoV6ProviderFactories: mockClientProviderFactory(mClient),
+				Config:                   caaResourceConfig(`issue "letsencrypt.org"`),
+				ExpectError:              regexp.MustCompile(`Invalid CAA record data`),
+			},
+		},
+	})
+}
+
 // check for NOOP if delete is performed on resource that is gone already
 func TestUnitMXNoopDelIfGone(t *testing.T) {
 	mData := model.DNSRecordData("mx1.test.com")
@@ -337,6 +504,32 @@ func TestUnitNSExtMod(t *testing.T) {
 	})
 }
 
+// API returns the same address in canonical form: no diff, configured value is kept,
+// delete matches it
+func TestUnitAAAANormalizedData(t *testing.T) {
+	mData := model.DNSRecordData("2001:DB8:0::1")
+	mType, mName, mRecs, tfResName := makeMockRec(model.REC_AAAA, mData)
+	mRecsAPI := slices.Clone(mRecs)
+	mRecsAPI[0].Data = "2001:db8::1"
+
+	mClient := model.NewMockDNSApiClient(t)
+	mClient.EXPECT().AddRecords(mCtx, mDom, mRecs).Return(nil).Once()
+	mClient.EXPECT().GetRecords(mCtx, mDom, mType, mName).Return(mRecsAPI, nil)
+	mClient.EXPECT().DelRecords(mCtx, mDom, mType, mName).Return(nil).Once()
+
+	resource.UnitTest(t, resource.TestCase{
+		Steps: []resource.TestStep{
+			{
+				ProtoV6ProviderFactories: mockClientProviderFactory(mClient),
+				Config:                   simpleResourceConfig(model.REC_AAAA, mData),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(tfResName, "data", string(mData)),
+				),
+			},
+		},
+	})
+}
+
 // test that modifications to TXT record are not affecting another TXT records
 // with the same name (by pre-creating one and checking it is ok afterwards)
 func TestAccTXTLifecycle(t *testing.T) {
@@ -556,6 +749,8 @@ func TestUnitCnameLifecycle(t *testing.T) {
 	mDataOther := model.DNSRecordData("changed.com")
 	mDataChanged := model.DNSRecordData("test.com")
 	mType, mName, mRecs, tfResName := makeMockRec(model.REC_CNAME, mData)
+	// does not include data, so stays the same on update
+	mID := fmt.Sprintf("%s:CNAME:%s", mDom, mName)
 
 	// add record, read it back
 	// also: calls DelRecord if step fails, mb add it as optional
@@ -596,6 +791,7 @@ func TestUnitCnameLifecycle(t *testing.T) {
 				Config:                   simpleResourceConfig(model.REC_CNAME, mData),
 				Check: resource.ComposeAggregateTestCheckFunc(
 					resource.TestCheckResourceAttr(tfResName, "data", string(mData)),
+					resource.TestCheckResourceAttr(tfResName, "id", mID),
 				),
 			},
 			// read, compare with saved, should produce no plan
@@ -624,12 +820,76 @@ func TestUnitCnameLifecycle(t *testing.T) {
 				Config:                   simpleResourceConfig(model.REC_CNAME, mDataChanged),
 				Check: resource.ComposeAggregateTestCheckFunc(
 					resource.TestCheckResourceAttr(tfResName, "data", string(mDataChanged)),
+					resource.TestCheckResourceAttr(tfResName, "id", mID),
 				),
 			},
 		},
 	})
 }
 
+// CRUD of CNAME record with default provider config (reads are cached): mock
+// keeps records like API does, so number of actual reads does not matter
+func TestUnitCnameCachedLifecycle(t *testing.T) {
+	mData := model.DNSRecordData("testing.com")
+	mDataChanged := model.DNSRecordData("test.com")
+	mType, mName, _, tfResName := makeMockRec(model.REC_CNAME, mData)
+
+	var mu sync.Mutex
+	var apiRecs []model.DNSRecord
+	mClient := model.NewMockDNSApiClient(t)
+	mClient.EXPECT().GetRecords(mCtx, mDom, mType, mName).RunAndReturn(
+		func(context.Context, model.DNSDomain, model.DNSRecordType, model.DNSRecordName) ([]model.DNSRecord, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			return slices.Clone(apiRecs), nil
+		})
+	mClient.EXPECT().AddRecords(mCtx, mDom, mock.Anything).RunAndReturn(
+		func(_ context.Context, _ model.DNSDomain, recs []model.DNSRecord) error {
+			mu.Lock()
+			defer mu.Unlock()
+			apiRecs = append(apiRecs, recs...)
+			return nil
+		}).Once()
+	mClient.EXPECT().SetRecords(mCtx, mDom, mType, mName, []model.DNSUpdateRecord{{Data: mDataChanged, TTL: 3600}}).RunAndReturn(
+		func(context.Context, model.DNSDomain, model.DNSRecordType, model.DNSRecordName, []model.DNSUpdateRecord) error {
+			mu.Lock()
+			defer mu.Unlock()
+			apiRecs[0].Data = mDataChanged
+			return nil
+		}).Once()
+	mClient.EXPECT().DelRecords(mCtx, mDom, mType, mName).RunAndReturn(
+		func(context.Context, model.DNSDomain, model.DNSRecordType, model.DNSRecordName) error {
+			mu.Lock()
+			defer mu.Unlock()
+			apiRecs = nil
+			return nil
+		}).Once()
+
+	config := func(data model.DNSRecordData) string {
+		return fmt.Sprintf(`
+		provider "godaddy-dns" {}
+		resource "godaddy-dns_record" "test-cname" {
+		  domain = "%s"
+		  type   = "CNAME"
+		  name   = "%s"
+		  data   = "%s"
+		}`, mDom, mName, data)
+	}
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: mockClientProviderFactory(mClient),
+		Steps: []resource.TestStep{
+			{
+				Config: config(mData),
+				Check:  resource.TestCheckResourceAttr(tfResName, "data", string(mData)),
+			},
+			{
+				Config: config(mDataChanged),
+				Check:  resource.TestCheckResourceAttr(tfResName, "data", string(mDataChanged)),
+			},
+		},
+	})
+}
+
 // simple acceptance test for CRUD of CNAME record
 func TestAccCnameLifecycle(t *testing.T) {
 	mData := model.DNSRecordData("testing.com")
@@ -672,3 +932,43 @@ func TestAccCnameLifecycle(t *testing.T) {
 		},
 	})
 }
+
+// SRV key fields are like data: changing them means another record
+func TestSRVKeyRequiresReplace(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	conf := recordConfig(t, map[string]tftypes.Value{
+		"domain": tftypes.NewValue(tftypes.String, "test.com"),
+		"type":   tftypes.NewValue(tftypes.String, "SRV"),
+		"name":   tftypes.NewValue(tftypes.String, "@"),
+	})
+	state := tfsdk.State{Schema: conf.Schema, Raw: conf.Raw}
+	plan := tfsdk.Plan{Schema: conf.Schema, Raw: conf.Raw}
+
+	for _, name := range []string{"service", "protocol"} {
+		attr := conf.Schema.GetAttributes()[name].(schema.StringAttribute)
+		resp := planmodifier.StringResponse{}
+		for _, m := range attr.PlanModifiers {
+			m.PlanModifyString(ctx, planmodifier.StringRequest{
+				Path: path.Root(name), Config: conf, State: state, Plan: plan,
+				StateValue: types.StringValue("_old"), PlanValue: types.StringValue("_new"),
+				ConfigValue: types.StringValue("_new"),
+			}, &resp)
+		}
+		if !resp.RequiresReplace {
+			t.Errorf("changing %s must require replace", name)
+		}
+	}
+	attr := conf.Schema.GetAttributes()["port"].(schema.Int64Attribute)
+	resp := planmodifier.Int64Response{}
+	for _, m := range attr.PlanModifiers {
+		m.PlanModifyInt64(ctx, planmodifier.Int64Request{
+			Path: path.Root("port"), Config: conf, State: state, Plan: plan,
+			StateValue: types.Int64Value(5060), PlanValue: types.Int64Value(5061),
+			ConfigValue: types.Int64Value(5061),
+		}, &resp)
+	}
+	if !resp.RequiresReplace {
+		t.Error("changing port must require replace")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"slices"
 	"strings"
 	"testing"
 	"text/template"
@@ -33,18 +34,25 @@ var testAccProtoV6ProviderFactories = map[string]func() (tfprotov6.ProviderServe
 	// pass "test" as version to the provider constructor
 	"godaddy-dns": providerserver.NewProtocol6WithError(New(
 		"test",
-		func(apiURL, apiKey, apiSecret string) (model.DNSApiClient, error) {
-			return client.NewClient(apiURL, apiKey, apiSecret)
+		func(apiURL, apiKey, apiSecret string, opts ...client.Option) (model.DNSApiClient, error) {
+			return client.NewClient(apiURL, apiKey, apiSecret, opts...)
 		})()),
 }
 
+// provider block for test configs: mock-based unit tests expect exact API
+// calls for every operation, so reads are not cached
+const testProviderConfig = `
+	provider "godaddy-dns" {
+	  cache_reads = false
+	}`
+
 // provider instantiation for unit tests: use mock API
 func mockClientProviderFactory(c *model.MockDNSApiClient) map[string]func() (tfprotov6.ProviderServer, error) {
 	return map[string]func() (tfprotov6.ProviderServer, error){
 		// pass "unittest" as version to the provider constructor
 		"godaddy-dns": providerserver.NewProtocol6WithError(New(
 			"unittest",
-			func(apiURL, apiKey, apiSecret string) (model.DNSApiClient, error) {
+			func(apiURL, apiKey, apiSecret string, opts ...client.Option) (model.DNSApiClient, error) {
 				return model.DNSApiClient(c), nil
 			})()),
 	}
@@ -68,8 +76,7 @@ func makeMockRec(mType model.DNSRecordType, mData model.DNSRecordData) (model.DN
 
 // create standard terraform config for test record of given type
 func simpleResourceConfig(rectype model.DNSRecordType, target model.DNSRecordData) string {
-	templateString := `
-	provider "godaddy-dns" {}
+	templateString := testProviderConfig + `
 	resource "godaddy-dns_record" "test-{{ .RecType | lower }}" {
 	  domain = "{{ .Domain }}"
 	  type   = "{{ .RecType | upper }}"
@@ -109,6 +116,33 @@ func simpleResourceConfig(rectype model.DNSRecordType, target model.DNSRecordDat
 	return buff.String()
 }
 
+// create terraform config for test SRV record (_sip._tcp on port 5060)
+func srvResourceConfig(target model.DNSRecordData, prio model.DNSRecordPrio, weight model.DNSRecordSRVWeight) string {
+	return testProviderConfig + fmt.Sprintf(`
+	resource "godaddy-dns_record" "test-srv" {
+	  domain   = "%s"
+	  type     = "SRV"
+	  name     = "test-srv._test"
+	  data     = "%s"
+	  priority = %d
+	  weight   = %d
+	  service  = "_sip"
+	  protocol = "_tcp"
+	  port     = 5060
+	}`, TEST_DOMAIN, target, prio, weight)
+}
+
+// create terraform config for test CAA record (data has quotes, so escape it)
+func caaResourceConfig(data model.DNSRecordData) string {
+	return testProviderConfig + fmt.Sprintf(`
+	resource "godaddy-dns_record" "test-caa" {
+	  domain = "%s"
+	  type   = "CAA"
+	  name   = "test-caa._test"
+	  data   = %q
+	}`, TEST_DOMAIN, data)
+}
+
 // - terraform config with N records
 // - domain record name for it ("test-<type>._test")
 // - terraform resource name for record type ("godaddy-dns_record.test-<type>")
@@ -126,8 +160,7 @@ type testRecSet struct {
 func makeTestRecSet(rectype model.DNSRecordType, values []model.DNSRecordData) testRecSet {
 	res := testRecSet{}
 
-	templateString := `
-	provider "godaddy-dns" {}
+	templateString := testProviderConfig + `
 	locals {
 	  dataValues = [{{ .RecValsJoined }}]
 	}
@@ -241,6 +274,21 @@ func CheckApiRecordMach(resourceName string, apiClient *client.Client) resource.
 	}
 }
 
+// mock argument matcher for update records ignoring the order (e.g. for sets)
+func updRecsInAnyOrder(want []model.DNSUpdateRecord) interface{} {
+	return mock.MatchedBy(func(got []model.DNSUpdateRecord) bool {
+		if len(got) != len(want) {
+			return false
+		}
+		for _, w := range want {
+			if !slices.Contains(got, w) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
 // helper function to use in mocks to trace execution path, like
 // mClientUpd.EXPECT().GetRecords(itsArgs).Once().Run(traceMarker("1st run"))
 func traceMarker(msg string) func(args mock.Arguments) {
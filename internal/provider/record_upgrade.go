@@ -0,0 +1,119 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/veksh/terraform-provider-godaddy-dns/internal/model"
+)
+
+// one version of record schema: its attributes and migration of raw state
+// from the previous version
+type recordSchemaVersion struct {
+	Attrs []string
+	// convert state attributes of previous version, nil for the initial one;
+	// attributes not present in Attrs are dropped after it
+	Migrate func(state map[string]any) error
+}
+
+var recordAttrsV0 = []string{
+	"domain", "type", "name", "data", "ttl", "priority",
+	"service", "protocol", "port", "weight", "shopper_id",
+}
+
+// record schema history, index is the schema version; any change to schema
+// attributes must add a new version here (checked by tests)
+//   - 0: initial version; SRV attributes and `shopper_id` were added without
+//     version bump, so they could be absent in older states (null then)
+//   - 1: computed `id`
+var recordSchemaHistory = []recordSchemaVersion{
+	{Attrs: recordAttrsV0},
+	{Attrs: append([]string{"id"}, recordAttrsV0...), Migrate: migrateRecordStateV1},
+}
+
+// current version of record schema
+func recordSchemaVersionNum() int64 {
+	return int64(len(recordSchemaHistory) - 1)
+}
+
+// states from previous schema versions are upgraded as raw JSON, passing
+// through all the migrations up to the current version: this way there is
+// no need to keep full schema (and tf model) for every version
+func (r *RecordResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	upgraders := map[int64]resource.StateUpgrader{}
+	for v := int64(0); v < recordSchemaVersionNum(); v++ {
+		fromVersion := v
+		upgraders[v] = resource.StateUpgrader{
+			StateUpgrader: func(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+				if req.RawState == nil || req.RawState.JSON == nil {
+					resp.Diagnostics.AddError("Unable to Upgrade Resource State",
+						"Expected record state in JSON format, got none")
+					return
+				}
+				upgraded, err := upgradeRecordState(req.RawState.JSON, fromVersion)
+				if err != nil {
+					resp.Diagnostics.AddError("Unable to Upgrade Resource State",
+						fmt.Sprintf("Cannot upgrade record state from schema version %d to %d: %s",
+							fromVersion, recordSchemaVersionNum(), err))
+					return
+				}
+				resp.DynamicValue = &tfprotov6.DynamicValue{JSON: upgraded}
+			},
+		}
+	}
+	return upgraders
+}
+
+// apply migrations to raw JSON state of given version
+func upgradeRecordState(rawState []byte, fromVersion int64) ([]byte, error) {
+	var state map[string]any
+	dec := json.NewDecoder(bytes.NewReader(rawState))
+	dec.UseNumber()
+	if err := dec.Decode(&state); err != nil {
+		return nil, err
+	}
+	for v := fromVersion + 1; v <= recordSchemaVersionNum(); v++ {
+		version := recordSchemaHistory[v]
+		if err := version.Migrate(state); err != nil {
+			return nil, fmt.Errorf("version %d: %w", v, err)
+		}
+		for name := range state {
+			if !slices.Contains(version.Attrs, name) {
+				delete(state, name)
+			}
+		}
+	}
+	return json.Marshal(state)
+}
+
+// 0 -> 1: add id computed from key fields
+func migrateRecordStateV1(state map[string]any) error {
+	str := func(name string) string {
+		s, _ := state[name].(string)
+		return s
+	}
+	rec := model.DNSRecord{
+		Type:     model.DNSRecordType(str("type")),
+		Name:     model.DNSRecordName(str("name")),
+		Data:     model.DNSRecordData(str("data")),
+		Service:  model.DNSRecordSRVService(str("service")),
+		Protocol: model.DNSRecordSRVProto(str("protocol")),
+	}
+	if port, ok := state["port"].(json.Number); ok {
+		p, err := port.Int64()
+		if err != nil {
+			return fmt.Errorf("port: %w", err)
+		}
+		rec.Port = model.DNSRecordSRVPort(p)
+	}
+	if str("domain") == "" || rec.Type == "" || rec.Name == "" {
+		return fmt.Errorf("domain, type and name must be set")
+	}
+	state["id"] = recordID(model.DNSDomain(str("domain")), rec)
+	return nil
+}
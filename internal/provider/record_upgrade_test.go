@@ -0,0 +1,195 @@
+package provider
+
+import (
+	"context"
+	"math/big"
+	"slices"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// upgrade raw state from given version, return result as current schema value
+func runRecordUpgrader(t *testing.T, version int64, rawState string) (map[string]tftypes.Value, resource.UpgradeStateResponse) {
+	t.Helper()
+	ctx := context.Background()
+	r := &RecordResource{}
+	schemaResp := resource.SchemaResponse{}
+	r.Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+	upgrader, ok := r.UpgradeState(ctx)[version]
+	if !ok {
+		t.Fatalf("no upgrader for version %d", version)
+	}
+	req := resource.UpgradeStateRequest{RawState: &tfprotov6.RawState{JSON: []byte(rawState)}}
+	resp := resource.UpgradeStateResponse{}
+	upgrader.StateUpgrader(ctx, req, &resp)
+	if resp.Diagnostics.HasError() {
+		return nil, resp
+	}
+	objType := schemaResp.Schema.Type().TerraformType(ctx)
+	val, err := resp.DynamicValue.Unmarshal(objType)
+	if err != nil {
+		t.Fatalf("upgraded state does not match current schema: %s", err)
+	}
+	attrs := map[string]tftypes.Value{}
+	if err := val.As(&attrs); err != nil {
+		t.Fatal(err)
+	}
+	return attrs, resp
+}
+
+func TestUpgradeRecordStateV0(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name  string
+		state string
+		want  map[string]any
+	}{
+		{
+			name: "CNAME",
+			state: `{"domain":"test.com","type":"CNAME","name":"www","data":"other.com","ttl":3600,` +
+				`"priority":null,"service":null,"protocol":null,"port":null,"weight":null,"shopper_id":null}`,
+			want: map[string]any{"id": "test.com:CNAME:www", "domain": "test.com", "type": "CNAME",
+				"name": "www", "data": "other.com", "ttl": int64(3600)},
+		},
+		{
+			name: "SRV",
+			state: `{"domain":"test.com","type":"SRV","name":"@","data":"sip.test.com","ttl":3600,` +
+				`"priority":10,"service":"_sip","protocol":"_tcp","port":5060,"weight":5,"shopper_id":"123"}`,
+			want: map[string]any{"id": "test.com:SRV:@:_sip:_tcp:5060:sip.test.com", "domain": "test.com",
+				"type": "SRV", "name": "@", "data": "sip.test.com", "ttl": int64(3600), "priority": int64(10),
+				"service": "_sip", "protocol": "_tcp", "port": int64(5060), "weight": int64(5), "shopper_id": "123"},
+		},
+		{
+			// 0.2.x: no SRV attributes and shopper id
+			name:  "without SRV attributes",
+			state: `{"domain":"test.com","type":"MX","name":"@","data":"mx1.test.com","ttl":3600,"priority":10}`,
+			want: map[string]any{"id": "test.com:MX:@:mx1.test.com", "domain": "test.com", "type": "MX",
+				"name": "@", "data": "mx1.test.com", "ttl": int64(3600), "priority": int64(10)},
+		},
+		{
+			name:  "unknown attribute dropped",
+			state: `{"domain":"test.com","type":"TXT","name":"@","data":"a: b","ttl":600,"legacy":true}`,
+			want: map[string]any{"id": `test.com:TXT:@:a\: b`, "domain": "test.com", "type": "TXT",
+				"name": "@", "data": "a: b", "ttl": int64(600)},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			attrs, resp := runRecordUpgrader(t, 0, tt.state)
+			if resp.Diagnostics.HasError() {
+				t.Fatal(resp.Diagnostics)
+			}
+			got := map[string]any{}
+			for name, val := range attrs {
+				switch {
+				case val.IsNull():
+					continue
+				case val.Type().Is(tftypes.String):
+					var s string
+					_ = val.As(&s)
+					got[name] = s
+				case val.Type().Is(tftypes.Number):
+					var n big.Float
+					_ = val.As(&n)
+					i, _ := n.Int64()
+					got[name] = i
+				}
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestUpgradeRecordStateV0_Bad(t *testing.T) {
+	t.Parallel()
+	for _, state := range []string{`not json`, `{"domain":"test.com","data":"1.2.3.4"}`} {
+		if _, resp := runRecordUpgrader(t, 0, state); !resp.Diagnostics.HasError() {
+			t.Errorf("want error for state %s", state)
+		}
+	}
+}
+
+func TestRecordIDPlanModifier(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	str := func(s string) tftypes.Value { return tftypes.NewValue(tftypes.String, s) }
+	for _, tc := range []struct {
+		data    tftypes.Value
+		wantID  string
+		unknown bool
+	}{
+		{data: str("1.2.3.4"), wantID: "test.com:A:www:1.2.3.4"},
+		{data: tftypes.NewValue(tftypes.String, tftypes.UnknownValue), unknown: true},
+	} {
+		conf := recordConfig(t, map[string]tftypes.Value{
+			"domain": str("test.com"), "type": str("A"), "name": str("www"), "data": tc.data,
+		})
+		req := planmodifier.StringRequest{
+			Plan:       tfsdk.Plan{Schema: conf.Schema, Raw: conf.Raw},
+			PlanValue:  types.StringUnknown(),
+			StateValue: types.StringNull(),
+		}
+		resp := planmodifier.StringResponse{PlanValue: req.PlanValue}
+		recordIDPlanModifier{}.PlanModifyString(ctx, req, &resp)
+		if resp.Diagnostics.HasError() {
+			t.Fatal(resp.Diagnostics)
+		}
+		if tc.unknown {
+			if !resp.PlanValue.IsUnknown() {
+				t.Errorf("want unknown id for unknown data, got %s", resp.PlanValue)
+			}
+		} else if resp.PlanValue.ValueString() != tc.wantID {
+			t.Errorf("want id %q, got %s", tc.wantID, resp.PlanValue)
+		}
+	}
+}
+
+// current schema must be the last one in history, and there must be an
+// upgrader for every previous version
+func TestRecordSchemaHistory(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	r := &RecordResource{}
+	schemaResp := resource.SchemaResponse{}
+	r.Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+
+	if schemaResp.Schema.Version != int64(len(recordSchemaHistory)-1) {
+		t.Errorf("schema version %d does not match history length %d",
+			schemaResp.Schema.Version, len(recordSchemaHistory))
+	}
+	attrs := []string{}
+	for name := range schemaResp.Schema.Attributes {
+		attrs = append(attrs, name)
+	}
+	last := slices.Clone(recordSchemaHistory[len(recordSchemaHistory)-1].Attrs)
+	slices.Sort(attrs)
+	slices.Sort(last)
+	if diff := cmp.Diff(last, attrs); diff != "" {
+		t.Errorf("schema attributes changed without new version in history: %s", diff)
+	}
+
+	upgraders := r.UpgradeState(ctx)
+	for v, version := range recordSchemaHistory {
+		_, ok := upgraders[int64(v)]
+		if v < len(recordSchemaHistory)-1 && !ok {
+			t.Errorf("no upgrader from version %d", v)
+		}
+		if v > 0 && version.Migrate == nil {
+			t.Errorf("no migration to version %d", v)
+		}
+	}
+	if len(upgraders) != len(recordSchemaHistory)-1 {
+		t.Errorf("want %d upgraders, got %d", len(recordSchemaHistory)-1, len(upgraders))
+	}
+}
@@ -0,0 +1,173 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/veksh/terraform-provider-godaddy-dns/internal/model"
+)
+
+var (
+	_ datasource.DataSource              = &RecordsDataSource{}
+	_ datasource.DataSourceWithConfigure = &RecordsDataSource{}
+)
+
+type tfDNSRecords struct {
+	Domain    types.String      `tfsdk:"domain"`
+	Type      types.String      `tfsdk:"type"`
+	Name      types.String      `tfsdk:"name"`
+	Records   []tfDNSZoneRecord `tfsdk:"records"`
+	ShopperID types.String      `tfsdk:"shopper_id"`
+}
+
+// RecordsDataSource returns existing records for domain, optionally
+// filtered by type and name
+type RecordsDataSource struct {
+	client model.DNSApiClient
+}
+
+func NewRecordsDataSource() datasource.DataSource {
+	return &RecordsDataSource{}
+}
+
+func (d *RecordsDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_records"
+}
+
+func (d *RecordsDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Existing DNS records in domain, optionally filtered by type and name",
+		Attributes: map[string]schema.Attribute{
+			"domain": schema.StringAttribute{
+				MarkdownDescription: "Name of main managed domain (top-level)",
+				Required:            true,
+			},
+			"type": schema.StringAttribute{
+				MarkdownDescription: "Return only records of this type: A, CNAME etc",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf([]string{"A", "AAAA", "CAA", "CNAME", "MX", "NS", "SOA", "SRV", "TXT"}...),
+				},
+			},
+			"name": schema.StringAttribute{
+				MarkdownDescription: "Return only records with this name, like `www` or `@`",
+				Optional:            true,
+			},
+			"shopper_id": schema.StringAttribute{
+				MarkdownDescription: "Shopper ID of reseller sub-account owning the domain, " +
+					"overrides provider `shopper_id`",
+				Optional: true,
+			},
+			"records": schema.ListNestedAttribute{
+				MarkdownDescription: "Matching records",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"type": schema.StringAttribute{
+							MarkdownDescription: "Resource record type",
+							Computed:            true,
+						},
+						"name": schema.StringAttribute{
+							MarkdownDescription: "Record name",
+							Computed:            true,
+						},
+						"data": schema.StringAttribute{
+							CustomType:          recordDataType{},
+							MarkdownDescription: "Record value",
+							Computed:            true,
+						},
+						"ttl": schema.Int64Attribute{
+							MarkdownDescription: "Record time-to-live",
+							Computed:            true,
+						},
+						"priority": schema.Int64Attribute{
+							MarkdownDescription: "Record priority (MX and SRV only)",
+							Computed:            true,
+						},
+						"service": schema.StringAttribute{
+							MarkdownDescription: "Service name (SRV only)",
+							Computed:            true,
+						},
+						"protocol": schema.StringAttribute{
+							MarkdownDescription: "Protocol (SRV only)",
+							Computed:            true,
+						},
+						"port": schema.Int64Attribute{
+							MarkdownDescription: "Service port (SRV only)",
+							Computed:            true,
+						},
+						"weight": schema.Int64Attribute{
+							MarkdownDescription: "Relative weight (SRV only)",
+							Computed:            true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *RecordsDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	pd, ok := req.ProviderData.(*providerData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Internal error: expected *providerData, got: %T", req.ProviderData),
+		)
+		return
+	}
+
+	d.client = pd.client
+}
+
+func (d *RecordsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var confData tfDNSRecords
+	resp.Diagnostics.Append(req.Config.Get(ctx, &confData)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx = tflog.SetField(ctx, "domain", confData.Domain.ValueString())
+	ctx = tflog.SetField(ctx, "type", confData.Type.ValueString())
+	ctx = tflog.SetField(ctx, "name", confData.Name.ValueString())
+	ctx = tflog.SetField(ctx, "operation", "query")
+	ctx = shopperCtx(ctx, confData.ShopperID)
+	tflog.Info(ctx, "query: start")
+	defer tflog.Info(ctx, "query: end")
+
+	apiDomain := model.DNSDomain(confData.Domain.ValueString())
+	apiType := model.DNSRecordType(confData.Type.ValueString())
+	apiName := model.DNSRecordName(confData.Name.ValueString())
+
+	// API could filter by name only together with type, so have to get all
+	// the records and filter them here
+	apiQueryName := apiName
+	if apiType == "" {
+		apiQueryName = ""
+	}
+	apiRecs, err := d.client.GetRecords(ctx, apiDomain, apiType, apiQueryName)
+	if err != nil {
+		addClientError(&resp.Diagnostics, "Reading DNS records: query failed", err)
+		return
+	}
+	tflog.Info(ctx, fmt.Sprintf("Reading DNS records: got %d answers", len(apiRecs)))
+
+	confData.Records = []tfDNSZoneRecord{}
+	for _, rec := range apiRecs {
+		if apiName != "" && rec.Name != apiName {
+			continue
+		}
+		confData.Records = append(confData.Records, model2tfZoneRecord(rec))
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &confData)...)
+}
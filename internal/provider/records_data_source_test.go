@@ -0,0 +1,109 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/veksh/terraform-provider-godaddy-dns/internal/model"
+)
+
+// query by type + name goes directly to API
+func TestUnitRecordsDataSourceTypeName(t *testing.T) {
+	mRecs := []model.DNSRecord{
+		{Type: model.REC_MX, Name: "@", Data: "mx1.test.com", TTL: 3600, Priority: 10},
+		{Type: model.REC_MX, Name: "@", Data: "mx2.test.com", TTL: 3600, Priority: 20},
+	}
+	mClient := model.NewMockDNSApiClient(t)
+	mClient.EXPECT().GetRecords(mCtx, mDom, model.REC_MX, model.DNSRecordName("@")).Return(mRecs, nil)
+
+	dsName := "data.godaddy-dns_records.mx"
+	resource.UnitTest(t, resource.TestCase{
+		Steps: []resource.TestStep{
+			{
+				ProtoV6ProviderFactories: mockClientProviderFactory(mClient),
+				Config: fmt.Sprintf(`
+				provider "godaddy-dns" {}
+				data "godaddy-dns_records" "mx" {
+				  domain = "%s"
+				  type   = "MX"
+				  name   = "@"
+				}`, TEST_DOMAIN),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(dsName, "records.#", "2"),
+					resource.TestCheckResourceAttr(dsName, "records.1.data", "mx2.test.com"),
+					resource.TestCheckResourceAttr(dsName, "records.1.priority", "20"),
+					resource.TestCheckNoResourceAttr(dsName, "records.1.port"),
+				),
+			},
+		},
+	})
+}
+
+// query by name only: get everything and filter
+func TestUnitRecordsDataSourceName(t *testing.T) {
+	mRecs := []model.DNSRecord{
+		{Type: model.REC_A, Name: "@", Data: "Parked", TTL: 600},
+		{Type: model.REC_SRV, Name: "@", Data: "sip.test.com", TTL: 3600,
+			Priority: 10, Weight: 5, Service: "_sip", Protocol: "_tcp", Port: 5060},
+		{Type: model.REC_CNAME, Name: "www", Data: "@", TTL: 3600},
+	}
+	mClient := model.NewMockDNSApiClient(t)
+	mClient.EXPECT().GetRecords(mCtx, mDom, model.DNSRecordType(""), model.DNSRecordName("")).Return(mRecs, nil)
+
+	dsName := "data.godaddy-dns_records.top"
+	resource.UnitTest(t, resource.TestCase{
+		Steps: []resource.TestStep{
+			{
+				ProtoV6ProviderFactories: mockClientProviderFactory(mClient),
+				Config: fmt.Sprintf(`
+				provider "godaddy-dns" {}
+				data "godaddy-dns_records" "top" {
+				  domain = "%s"
+				  name   = "@"
+				}`, TEST_DOMAIN),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(dsName, "records.#", "2"),
+					resource.TestCheckResourceAttr(dsName, "records.0.data", "Parked"),
+					resource.TestCheckResourceAttr(dsName, "records.1.service", "_sip"),
+					resource.TestCheckResourceAttr(dsName, "records.1.port", "5060"),
+				),
+			},
+		},
+	})
+}
+
+// records are shared with zone resource: must round-trip through state
+func TestRecordsDataSourceStateRoundTrip(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	schemaResp := datasource.SchemaResponse{}
+	NewRecordsDataSource().Schema(ctx, datasource.SchemaRequest{}, &schemaResp)
+	if schemaResp.Diagnostics.HasError() {
+		t.Fatal(schemaResp.Diagnostics)
+	}
+	state := tfsdk.State{Schema: schemaResp.Schema}
+	want := tfDNSRecords{
+		Domain: types.StringValue("test.com"),
+		Type:   types.StringValue("AAAA"),
+		Name:   types.StringValue("www"),
+		Records: []tfDNSZoneRecord{
+			model2tfZoneRecord(model.DNSRecord{Type: model.REC_AAAA, Name: "www", Data: "2001:db8::1", TTL: 3600}),
+		},
+		ShopperID: types.StringNull(),
+	}
+	if diags := state.Set(ctx, &want); diags.HasError() {
+		t.Fatal(diags)
+	}
+	var got tfDNSRecords
+	if diags := state.Get(ctx, &got); diags.HasError() {
+		t.Fatal(diags)
+	}
+	if len(got.Records) != 1 || got.Records[0].Data.ValueString() != "2001:db8::1" {
+		t.Errorf("unexpected records read back: %+v", got.Records)
+	}
+}
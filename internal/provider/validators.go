@@ -0,0 +1,46 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/veksh/terraform-provider-godaddy-dns/internal/model"
+)
+
+var _ resource.ConfigValidator = recordDataValidator{}
+
+// check record data format depending on record type (see model.ValidateData):
+// attribute validators see only their own value, so it has to be done on the
+// resource level
+type recordDataValidator struct{}
+
+func (v recordDataValidator) Description(ctx context.Context) string {
+	return "record data must be valid for the record type"
+}
+
+func (v recordDataValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v recordDataValidator) ValidateResource(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var recType types.String
+	var recData recordDataValue
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("type"), &recType)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("data"), &recData)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	// could be unknown until apply, e.g. if set from another resource
+	if recType.IsNull() || recType.IsUnknown() || recData.IsNull() || recData.IsUnknown() {
+		return
+	}
+
+	rType := model.DNSRecordType(recType.ValueString())
+	if err := model.ValidateData(rType, model.DNSRecordData(recData.ValueString())); err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("data"),
+			fmt.Sprintf("Invalid %s record data", rType), err.Error())
+	}
+}
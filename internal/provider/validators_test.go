@@ -0,0 +1,213 @@
+package provider
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// record resource config with given string attributes, others are null
+func recordConfig(t *testing.T, attrs map[string]tftypes.Value) tfsdk.Config {
+	t.Helper()
+	return resourceConfig(t, &RecordResource{}, attrs)
+}
+
+// resource config with given attributes, others are null
+func resourceConfig(t *testing.T, r resource.Resource, attrs map[string]tftypes.Value) tfsdk.Config {
+	t.Helper()
+	ctx := context.Background()
+	schemaResp := resource.SchemaResponse{}
+	r.Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+	objType := schemaResp.Schema.Type().TerraformType(ctx).(tftypes.Object)
+	vals := map[string]tftypes.Value{}
+	for name, attrType := range objType.AttributeTypes {
+		if v, ok := attrs[name]; ok {
+			vals[name] = v
+		} else {
+			vals[name] = tftypes.NewValue(attrType, nil)
+		}
+	}
+	return tfsdk.Config{
+		Schema: schemaResp.Schema,
+		Raw:    tftypes.NewValue(objType, vals),
+	}
+}
+
+func TestRecordDataValidator(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		rType   string
+		data    tftypes.Value
+		wantErr bool
+	}{
+		{"A", tftypes.NewValue(tftypes.String, "1.2.3.4"), false},
+		{"A", tftypes.NewValue(tftypes.String, "1.2.3"), true},
+		{"AAAA", tftypes.NewValue(tftypes.String, "1.2.3.4"), true},
+		{"CNAME", tftypes.NewValue(tftypes.String, "1.2.3.4"), true},
+		{"MX", tftypes.NewValue(tftypes.String, "mx1.test.com"), false},
+		{"CAA", tftypes.NewValue(tftypes.String, `issue "letsencrypt.org"`), true},
+		// not known until apply
+		{"A", tftypes.NewValue(tftypes.String, tftypes.UnknownValue), false},
+	}
+	for _, tt := range tests {
+		req := resource.ValidateConfigRequest{
+			Config: recordConfig(t, map[string]tftypes.Value{
+				"type": tftypes.NewValue(tftypes.String, tt.rType),
+				"data": tt.data,
+			}),
+		}
+		resp := resource.ValidateConfigResponse{}
+		recordDataValidator{}.ValidateResource(context.Background(), req, &resp)
+		if resp.Diagnostics.HasError() != tt.wantErr {
+			t.Errorf("%s %s: want error %v, got %v", tt.rType, tt.data, tt.wantErr, resp.Diagnostics)
+			continue
+		}
+		for _, d := range resp.Diagnostics.Errors() {
+			if withPath, ok := d.(interface{ Path() path.Path }); !ok || !withPath.Path().Equal(path.Root("data")) {
+				t.Errorf("%s %s: error is not attached to data: %v", tt.rType, tt.data, d)
+			}
+		}
+	}
+}
+
+func TestRecordSetValidateConfig(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	schemaResp := resource.SchemaResponse{}
+	(&RecordSetResource{}).Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+	objType := schemaResp.Schema.Type().TerraformType(ctx).(tftypes.Object)
+	valType := objType.AttributeTypes["records"].(tftypes.Set).ElementType.(tftypes.Object)
+	value := func(data string, prio any) tftypes.Value {
+		return tftypes.NewValue(valType, map[string]tftypes.Value{
+			"data":     tftypes.NewValue(tftypes.String, data),
+			"ttl":      tftypes.NewValue(tftypes.Number, nil),
+			"priority": tftypes.NewValue(tftypes.Number, prio),
+		})
+	}
+	tests := []struct {
+		name    string
+		rType   string
+		vals    []tftypes.Value
+		wantErr bool
+	}{
+		{"MX with priority", "MX", []tftypes.Value{value("mx1.test.com", 10)}, false},
+		{"MX without priority", "MX", []tftypes.Value{value("mx1.test.com", nil)}, true},
+		{"TXT without priority", "TXT", []tftypes.Value{value("one", nil), value("two", nil)}, false},
+		{"TXT with priority", "TXT", []tftypes.Value{value("one", nil), value("two", 10)}, true},
+		{"A with unknown priority", "A", []tftypes.Value{value("1.2.3.4", tftypes.UnknownValue)}, true},
+		{"several CNAMEs", "CNAME", []tftypes.Value{value("one.com", nil), value("two.com", nil)}, true},
+		{"A with invalid address", "A", []tftypes.Value{value("1.2.3.4", nil), value("1.2.3", nil)}, true},
+		{"CAA without flags", "CAA", []tftypes.Value{value(`issue "letsencrypt.org"`, nil)}, true},
+		{"valid CAA", "CAA", []tftypes.Value{value(`0 issue "letsencrypt.org"`, nil)}, false},
+	}
+	for _, tt := range tests {
+		req := resource.ValidateConfigRequest{
+			Config: resourceConfig(t, &RecordSetResource{}, map[string]tftypes.Value{
+				"type":    tftypes.NewValue(tftypes.String, tt.rType),
+				"records": tftypes.NewValue(objType.AttributeTypes["records"], tt.vals),
+			}),
+		}
+		resp := resource.ValidateConfigResponse{}
+		(&RecordSetResource{}).ValidateConfig(ctx, req, &resp)
+		if resp.Diagnostics.HasError() != tt.wantErr {
+			t.Errorf("%s: want error %v, got %v", tt.name, tt.wantErr, resp.Diagnostics)
+		}
+	}
+}
+
+func TestZoneValidateConfig(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	schemaResp := resource.SchemaResponse{}
+	(&ZoneResource{}).Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+	objType := schemaResp.Schema.Type().TerraformType(ctx).(tftypes.Object)
+	recType := objType.AttributeTypes["records"].(tftypes.Set).ElementType.(tftypes.Object)
+	// record of given type, name "@", with attributes overridden by extra
+	record := func(rType, data string, extra map[string]any) tftypes.Value {
+		vals := map[string]tftypes.Value{}
+		for name, attrType := range recType.AttributeTypes {
+			vals[name] = tftypes.NewValue(attrType, extra[name])
+		}
+		vals["type"] = tftypes.NewValue(tftypes.String, rType)
+		vals["name"] = tftypes.NewValue(tftypes.String, "@")
+		vals["data"] = tftypes.NewValue(tftypes.String, data)
+		return tftypes.NewValue(recType, vals)
+	}
+	srvFields := map[string]any{
+		"priority": 10, "weight": 5, "service": "_sip", "protocol": "_tcp", "port": 5060,
+	}
+	tests := []struct {
+		name    string
+		rec     tftypes.Value
+		wantErr bool
+	}{
+		{"A", record("A", "1.2.3.4", nil), false},
+		{"MX with priority", record("MX", "mx.test.com", map[string]any{"priority": 10}), false},
+		{"MX without priority", record("MX", "mx.test.com", nil), true},
+		{"SRV with all fields", record("SRV", "sip.test.com", srvFields), false},
+		{"SRV without port", record("SRV", "sip.test.com", map[string]any{
+			"priority": 10, "weight": 5, "service": "_sip", "protocol": "_tcp"}), true},
+		{"A with priority", record("A", "1.2.3.4", map[string]any{"priority": 10}), true},
+		{"TXT with unknown priority", record("TXT", "text", map[string]any{"priority": tftypes.UnknownValue}), true},
+		{"MX with weight", record("MX", "mx.test.com", map[string]any{"priority": 10, "weight": 5}), true},
+		{"CNAME with port", record("CNAME", "www.test.com", map[string]any{"port": 443}), true},
+		{"AAAA with IPv4 address", record("AAAA", "1.2.3.4", nil), true},
+		{"CNAME with IP address", record("CNAME", "1.2.3.4", nil), true},
+		{"TXT with too long string", record("TXT", strings.Repeat("x", 256), nil), true},
+	}
+	for _, tt := range tests {
+		req := resource.ValidateConfigRequest{
+			Config: resourceConfig(t, &ZoneResource{}, map[string]tftypes.Value{
+				"records": tftypes.NewValue(objType.AttributeTypes["records"], []tftypes.Value{tt.rec}),
+			}),
+		}
+		resp := resource.ValidateConfigResponse{}
+		(&ZoneResource{}).ValidateConfig(ctx, req, &resp)
+		if resp.Diagnostics.HasError() != tt.wantErr {
+			t.Errorf("%s: want error %v, got %v", tt.name, tt.wantErr, resp.Diagnostics)
+		}
+	}
+}
+
+// numeric fields are converted into uint16 API types, so out of range values
+// must be rejected, not wrapped
+func TestZoneRecordFieldRanges(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	schemaResp := resource.SchemaResponse{}
+	(&ZoneResource{}).Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+	attrs := schemaResp.Schema.Attributes["records"].(schema.SetNestedAttribute).NestedObject.Attributes
+	tests := []struct {
+		attr    string
+		value   int64
+		wantErr bool
+	}{
+		{"priority", 0, false},
+		{"priority", 1023, false},
+		{"priority", 1024, true},
+		{"priority", -1, true},
+		{"port", 65535, false},
+		{"port", 70000, true},
+		{"port", 0, true},
+		{"weight", 0, false},
+		{"weight", 65536, true},
+	}
+	for _, tt := range tests {
+		req := validator.Int64Request{Path: path.Root(tt.attr), ConfigValue: types.Int64Value(tt.value)}
+		resp := validator.Int64Response{}
+		for _, v := range attrs[tt.attr].(schema.Int64Attribute).Validators {
+			v.ValidateInt64(ctx, req, &resp)
+		}
+		if resp.Diagnostics.HasError() != tt.wantErr {
+			t.Errorf("%s = %d: want error %v, got %v", tt.attr, tt.value, tt.wantErr, resp.Diagnostics)
+		}
+	}
+}
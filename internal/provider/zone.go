@@ -0,0 +1,567 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	stdpath "path"
+	"regexp"
+	"slices"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64default"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/veksh/terraform-provider-godaddy-dns/internal/model"
+)
+
+var (
+	_ resource.Resource                   = &ZoneResource{}
+	_ resource.ResourceWithConfigure      = &ZoneResource{}
+	_ resource.ResourceWithImportState    = &ZoneResource{}
+	_ resource.ResourceWithValidateConfig = &ZoneResource{}
+)
+
+// records managed by GoDaddy itself: never touched by zone resource
+var zoneDefaultIgnore = []zoneIgnoreFilter{
+	{Type: model.REC_SOA, Name: "*"},
+	{Type: model.REC_NS, Name: "@"},
+	{Type: model.REC_CNAME, Name: "_domainconnect"},
+}
+
+type tfDNSZone struct {
+	Domain    types.String      `tfsdk:"domain"`
+	Records   []tfDNSZoneRecord `tfsdk:"records"`
+	Ignore    []tfDNSZoneIgnore `tfsdk:"ignore"`
+	ShopperID types.String      `tfsdk:"shopper_id"`
+}
+
+type tfDNSZoneRecord struct {
+	Type     types.String    `tfsdk:"type"`
+	Name     types.String    `tfsdk:"name"`
+	Data     recordDataValue `tfsdk:"data"`
+	TTL      types.Int64     `tfsdk:"ttl"`
+	Priority types.Int64     `tfsdk:"priority"`
+	Service  types.String    `tfsdk:"service"`
+	Protocol types.String    `tfsdk:"protocol"`
+	Port     types.Int64     `tfsdk:"port"`
+	Weight   types.Int64     `tfsdk:"weight"`
+}
+
+type tfDNSZoneIgnore struct {
+	Type types.String `tfsdk:"type"`
+	Name types.String `tfsdk:"name"`
+}
+
+// record is ignored if it matches both type (empty: any) and name (shell pattern)
+type zoneIgnoreFilter struct {
+	Type model.DNSRecordType
+	Name string
+}
+
+func (f zoneIgnoreFilter) Matches(rec model.DNSRecord) bool {
+	if f.Type != "" && f.Type != rec.Type {
+		return false
+	}
+	if f.Name == "" {
+		return true
+	}
+	matched, err := stdpath.Match(f.Name, string(rec.Name))
+	return err == nil && matched
+}
+
+// type + name: unit of update for GoDaddy API
+type rrSetKey struct {
+	Type model.DNSRecordType
+	Name model.DNSRecordName
+}
+
+// minimal set of API calls to turn current records into desired ones
+type zoneChanges struct {
+	// replace all the records for type + name
+	Set map[rrSetKey][]model.DNSUpdateRecord
+	// delete all the records for type + name
+	Del []rrSetKey
+}
+
+// compare current and desired records grouped by type + name: groups that are
+// the same (in any order, with equivalent data) are left alone, the rest are
+// replaced or deleted
+func diffZone(current, desired []model.DNSRecord) zoneChanges {
+	groupRecs := func(recs []model.DNSRecord) map[rrSetKey][]model.DNSUpdateRecord {
+		res := map[rrSetKey][]model.DNSUpdateRecord{}
+		for _, rec := range recs {
+			key := rrSetKey{rec.Type, rec.Name}
+			res[key] = append(res[key], rec.ToUpdate())
+		}
+		return res
+	}
+	curGroups := groupRecs(current)
+	desGroups := groupRecs(desired)
+
+	res := zoneChanges{Set: map[rrSetKey][]model.DNSUpdateRecord{}}
+	for key, desRecs := range desGroups {
+		if !sameUpdateRecs(key.Type, curGroups[key], desRecs) {
+			res.Set[key] = desRecs
+		}
+	}
+	for key := range curGroups {
+		if _, ok := desGroups[key]; !ok {
+			res.Del = append(res.Del, key)
+		}
+	}
+	sortRRSetKeys(res.Del)
+	return res
+}
+
+// just to make order of operations predictable
+func sortRRSetKeys(keys []rrSetKey) {
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].Type < keys[j].Type ||
+			(keys[i].Type == keys[j].Type && keys[i].Name < keys[j].Name)
+	})
+}
+
+// same records, ignoring the order; data is compared in normalized form
+// (see model.NormalizeData), as API could return it differently
+func sameUpdateRecs(rType model.DNSRecordType, a, b []model.DNSUpdateRecord) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	rest := slices.Clone(b)
+	for _, r := range a {
+		i := slices.IndexFunc(rest, func(r1 model.DNSUpdateRecord) bool {
+			return sameUpdateRec(rType, r, r1)
+		})
+		if i < 0 {
+			return false
+		}
+		rest = slices.Delete(rest, i, i+1)
+	}
+	return true
+}
+
+// same record fields, data is compared as equivalent for record type
+func sameUpdateRec(rType model.DNSRecordType, r, r1 model.DNSUpdateRecord) bool {
+	d, d1 := r.Data, r1.Data
+	r.Data, r1.Data = "", ""
+	return r == r1 && model.EquivalentData(rType, d, d1)
+}
+
+// convert from terraform data model into api data model
+func tfZone2model(tfData tfDNSZone) (model.DNSDomain, []model.DNSRecord, []zoneIgnoreFilter) {
+	recs := make([]model.DNSRecord, 0, len(tfData.Records))
+	for _, r := range tfData.Records {
+		recs = append(recs, model.DNSRecord{
+			Type:     model.DNSRecordType(r.Type.ValueString()),
+			Name:     model.DNSRecordName(r.Name.ValueString()),
+			Data:     model.DNSRecordData(r.Data.ValueString()),
+			TTL:      model.DNSRecordTTL(r.TTL.ValueInt64()),
+			Priority: model.DNSRecordPrio(r.Priority.ValueInt64()),
+			Service:  model.DNSRecordSRVService(r.Service.ValueString()),
+			Protocol: model.DNSRecordSRVProto(r.Protocol.ValueString()),
+			Port:     model.DNSRecordSRVPort(r.Port.ValueInt64()),
+			Weight:   model.DNSRecordSRVWeight(r.Weight.ValueInt64()),
+		})
+	}
+	filters := slices.Clone(zoneDefaultIgnore)
+	for _, f := range tfData.Ignore {
+		filters = append(filters, zoneIgnoreFilter{
+			Type: model.DNSRecordType(f.Type.ValueString()),
+			Name: f.Name.ValueString(),
+		})
+	}
+	return model.DNSDomain(tfData.Domain.ValueString()), recs, filters
+}
+
+// convert api record into terraform one: type-specific fields are null for other types
+func model2tfZoneRecord(rec model.DNSRecord) tfDNSZoneRecord {
+	res := tfDNSZoneRecord{
+		Type:     types.StringValue(string(rec.Type)),
+		Name:     types.StringValue(string(rec.Name)),
+		Data:     newRecordData(rec.Data),
+		TTL:      types.Int64Value(int64(rec.TTL)),
+		Priority: types.Int64Null(),
+		Service:  types.StringNull(),
+		Protocol: types.StringNull(),
+		Port:     types.Int64Null(),
+		Weight:   types.Int64Null(),
+	}
+	switch rec.Type {
+	case model.REC_MX:
+		res.Priority = types.Int64Value(int64(rec.Priority))
+	case model.REC_SRV:
+		res.Priority = types.Int64Value(int64(rec.Priority))
+		res.Service = types.StringValue(string(rec.Service))
+		res.Protocol = types.StringValue(string(rec.Protocol))
+		res.Port = types.Int64Value(int64(rec.Port))
+		res.Weight = types.Int64Value(int64(rec.Weight))
+	}
+	return res
+}
+
+// drop records matching any of the filters
+func filterIgnored(recs []model.DNSRecord, filters []zoneIgnoreFilter) []model.DNSRecord {
+	return slices.DeleteFunc(slices.Clone(recs), func(rec model.DNSRecord) bool {
+		return slices.ContainsFunc(filters, func(f zoneIgnoreFilter) bool {
+			return f.Matches(rec)
+		})
+	})
+}
+
+// ZoneResource manages all the records in the domain (except ignored)
+type ZoneResource struct {
+	client model.DNSApiClient
+	locker *recordLocker
+}
+
+func ZoneResourceFactory(l *recordLocker) func() resource.Resource {
+	return func() resource.Resource {
+		return &ZoneResource{locker: l}
+	}
+}
+
+// zone operations lock the whole domain
+func (r *ZoneResource) lock(tfZone tfDNSZone) func() {
+	return r.locker.LockDomain(model.DNSDomain(tfZone.Domain.ValueString()))
+}
+
+func (r *ZoneResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_zone"
+}
+
+func (r *ZoneResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "DNS zone represents all the records in managed domain: records not in configuration are removed",
+		Attributes: map[string]schema.Attribute{
+			"domain": schema.StringAttribute{
+				MarkdownDescription: "Name of managed domain (top-level)",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"shopper_id": schema.StringAttribute{
+				MarkdownDescription: "Shopper ID of reseller sub-account owning the domain, " +
+					"overrides provider `shopper_id`",
+				Optional: true,
+			},
+			"records": schema.SetNestedAttribute{
+				MarkdownDescription: "All the records in the domain, except for ignored ones",
+				Required:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"type": schema.StringAttribute{
+							MarkdownDescription: "Resource record type: A, CNAME etc",
+							Required:            true,
+							Validators: []validator.String{
+								stringvalidator.OneOf([]string{"A", "AAAA", "CAA", "CNAME", "MX", "NS", "SRV", "TXT"}...),
+							},
+						},
+						"name": schema.StringAttribute{
+							MarkdownDescription: "Record name (part of FQN), may include `.` for records in sub-domains or be `@` for top-level records",
+							Required:            true,
+						},
+						"data": schema.StringAttribute{
+							CustomType:          recordDataType{},
+							MarkdownDescription: "Record value returned for DNS query: target for CNAME, ip address for A etc",
+							Required:            true,
+						},
+						"ttl": schema.Int64Attribute{
+							MarkdownDescription: "Record time-to-live, >= 600s <= 604800s (1 week), default 3600 seconds (1 hour)",
+							Optional:            true,
+							Computed:            true,
+							Default:             int64default.StaticInt64(3600),
+							Validators: []validator.Int64{
+								int64validator.AtLeast(600),
+								int64validator.AtMost(604800),
+							},
+						},
+						"priority": schema.Int64Attribute{
+							MarkdownDescription: "Record priority, required for MX and SRV and not allowed for other types (lower is higher)",
+							Optional:            true,
+							Validators: []validator.Int64{
+								int64validator.AtLeast(0),
+								int64validator.AtMost(1023),
+							},
+						},
+						"service": schema.StringAttribute{
+							MarkdownDescription: "Service name for SRV records, like `_ldap` or `_sip`",
+							Optional:            true,
+							Validators: []validator.String{
+								stringvalidator.RegexMatches(regexp.MustCompile(`^_[a-zA-Z0-9-]+$`),
+									"must start with `_` followed by letters, digits or `-`"),
+							},
+						},
+						"protocol": schema.StringAttribute{
+							MarkdownDescription: "Protocol for SRV records: `_tcp`, `_udp` or `_tls`",
+							Optional:            true,
+							Validators: []validator.String{
+								stringvalidator.OneOf([]string{"_tcp", "_udp", "_tls"}...),
+							},
+						},
+						"port": schema.Int64Attribute{
+							MarkdownDescription: "Service port for SRV records, 1-65535",
+							Optional:            true,
+							Validators: []validator.Int64{
+								int64validator.AtLeast(1),
+								int64validator.AtMost(65535),
+							},
+						},
+						"weight": schema.Int64Attribute{
+							MarkdownDescription: "Relative weight for SRV records with the same priority",
+							Optional:            true,
+							Validators: []validator.Int64{
+								int64validator.AtLeast(0),
+								int64validator.AtMost(65535),
+							},
+						},
+					},
+				},
+			},
+			"ignore": schema.ListNestedAttribute{
+				MarkdownDescription: "Additional filters for records to leave alone (SOA, top-level NS and `_domainconnect` CNAME are always ignored)",
+				Optional:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"type": schema.StringAttribute{
+							MarkdownDescription: "Record type to ignore, any if not set",
+							Optional:            true,
+						},
+						"name": schema.StringAttribute{
+							MarkdownDescription: "Record name to ignore, could be a shell pattern like `_acme-challenge*`; any if not set",
+							Optional:            true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// checks involving several attributes: data format and required and unsupported
+// per-type fields, CNAME uniqueness, no records matching ignore filters
+func (r *ZoneResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var confData tfDNSZone
+	var recSet types.Set
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("records"), &recSet)...)
+	if resp.Diagnostics.HasError() || recSet.IsNull() || recSet.IsUnknown() {
+		return
+	}
+	resp.Diagnostics.Append(recSet.ElementsAs(ctx, &confData.Records, false)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("ignore"), &confData.Ignore)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	_, recs, filters := tfZone2model(confData)
+	cnames := map[model.DNSRecordName]int{}
+	for i, rec := range recs {
+		tfRec := confData.Records[i]
+		if tfRec.Type.IsUnknown() || tfRec.Name.IsUnknown() {
+			continue
+		}
+		desc := fmt.Sprintf("%s record %q", rec.Type, rec.Name)
+		if !(tfRec.Data.IsNull() || tfRec.Data.IsUnknown()) {
+			if err := model.ValidateData(rec.Type, rec.Data); err != nil {
+				resp.Diagnostics.AddAttributeError(path.Root("records"),
+					fmt.Sprintf("Invalid %s record data", rec.Type), err.Error())
+			}
+		}
+		switch rec.Type {
+		case model.REC_MX:
+			if tfRec.Priority.IsNull() {
+				resp.Diagnostics.AddAttributeError(path.Root("records"),
+					"Missing priority", "Priority is required for "+desc)
+			}
+		case model.REC_SRV:
+			if tfRec.Priority.IsNull() || tfRec.Weight.IsNull() || tfRec.Service.IsNull() ||
+				tfRec.Protocol.IsNull() || tfRec.Port.IsNull() {
+				resp.Diagnostics.AddAttributeError(path.Root("records"),
+					"Missing SRV fields", "Priority, weight, service, protocol and port are required for "+desc)
+			}
+		case model.REC_CNAME:
+			cnames[rec.Name] += 1
+			if cnames[rec.Name] == 2 {
+				resp.Diagnostics.AddAttributeError(path.Root("records"),
+					"Duplicate CNAME", "There could be only one "+desc)
+			}
+		}
+		// would be lost on read, resulting in diff on every plan
+		if rec.Type != model.REC_MX && rec.Type != model.REC_SRV && !tfRec.Priority.IsNull() {
+			resp.Diagnostics.AddAttributeError(path.Root("records"),
+				"Unexpected priority", "Priority is only supported for MX and SRV, set for "+desc)
+		}
+		if rec.Type != model.REC_SRV && !(tfRec.Weight.IsNull() && tfRec.Service.IsNull() &&
+			tfRec.Protocol.IsNull() && tfRec.Port.IsNull()) {
+			resp.Diagnostics.AddAttributeError(path.Root("records"),
+				"Unexpected SRV fields", "Weight, service, protocol and port are only supported for SRV, set for "+desc)
+		}
+		if slices.ContainsFunc(filters, func(f zoneIgnoreFilter) bool { return f.Matches(rec) }) {
+			resp.Diagnostics.AddAttributeError(path.Root("records"),
+				"Ignored record", desc+" matches ignore filter and cannot be managed")
+		}
+	}
+}
+
+func (r *ZoneResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	pd, ok := req.ProviderData.(*providerData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Internal error: expected *providerData, got: %T", req.ProviderData),
+		)
+		return
+	}
+
+	r.client = pd.client
+}
+
+func (r *ZoneResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var planData tfDNSZone
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &planData)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx = tflog.SetField(ctx, "domain", planData.Domain.ValueString())
+	ctx = tflog.SetField(ctx, "operation", "create")
+	ctx = shopperCtx(ctx, planData.ShopperID)
+	tflog.Info(ctx, "create: start")
+	defer tflog.Info(ctx, "create: end")
+	unlock := r.lock(planData)
+	defer unlock()
+
+	if err := r.syncZone(ctx, planData); err != nil {
+		addClientError(&resp.Diagnostics, "Unable to set zone records", err)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &planData)...)
+}
+
+func (r *ZoneResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var stateData tfDNSZone
+	resp.Diagnostics.Append(req.State.Get(ctx, &stateData)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx = tflog.SetField(ctx, "domain", stateData.Domain.ValueString())
+	ctx = tflog.SetField(ctx, "operation", "read")
+	ctx = shopperCtx(ctx, stateData.ShopperID)
+	tflog.Info(ctx, "read: start")
+	defer tflog.Info(ctx, "read: end")
+	unlock := r.lock(stateData)
+	defer unlock()
+
+	apiDomain, apiRecsState, filters := tfZone2model(stateData)
+	apiAllRecs, err := r.client.GetRecords(ctx, apiDomain, "", "")
+	if err != nil {
+		addClientError(&resp.Diagnostics, "Reading DNS records: query failed", err)
+		return
+	}
+	apiRecs := filterIgnored(apiAllRecs, filters)
+	tflog.Info(ctx, fmt.Sprintf("Reading DNS zone: got %d records, %d managed",
+		len(apiAllRecs), len(apiRecs)))
+
+	stateData.Records = make([]tfDNSZoneRecord, 0, len(apiRecs))
+	for _, rec := range apiRecs {
+		stateData.Records = append(stateData.Records, model2tfZoneRecord(keepStateData(rec, apiRecsState)))
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &stateData)...)
+}
+
+// zone is authoritative: compare plan with actual records, not the state
+func (r *ZoneResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var planData tfDNSZone
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &planData)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx = tflog.SetField(ctx, "domain", planData.Domain.ValueString())
+	ctx = tflog.SetField(ctx, "operation", "update")
+	ctx = shopperCtx(ctx, planData.ShopperID)
+	tflog.Info(ctx, "update: start")
+	defer tflog.Info(ctx, "update: end")
+	unlock := r.lock(planData)
+	defer unlock()
+
+	if err := r.syncZone(ctx, planData); err != nil {
+		addClientError(&resp.Diagnostics, "Updating zone records failed", err)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &planData)...)
+}
+
+// remove all the managed records, ignored ones are left intact
+func (r *ZoneResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var stateData tfDNSZone
+	resp.Diagnostics.Append(req.State.Get(ctx, &stateData)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx = tflog.SetField(ctx, "domain", stateData.Domain.ValueString())
+	ctx = tflog.SetField(ctx, "operation", "delete")
+	ctx = shopperCtx(ctx, stateData.ShopperID)
+	tflog.Info(ctx, "delete: start")
+	defer tflog.Info(ctx, "delete: end")
+	unlock := r.lock(stateData)
+	defer unlock()
+
+	stateData.Records = nil
+	if err := r.syncZone(ctx, stateData); err != nil {
+		addClientError(&resp.Diagnostics, "Deleting zone records failed", err)
+		return
+	}
+}
+
+// terraform import godaddy-dns_zone.main mydom.com
+func (r *ZoneResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("domain"), req, resp)
+}
+
+// read current records, apply minimal changes to make them match tfData
+func (r *ZoneResource) syncZone(ctx context.Context, tfData tfDNSZone) error {
+	apiDomain, apiRecsPlan, filters := tfZone2model(tfData)
+	apiAllRecs, err := r.client.GetRecords(ctx, apiDomain, "", "")
+	if err != nil {
+		return err
+	}
+	changes := diffZone(filterIgnored(apiAllRecs, filters), apiRecsPlan)
+	tflog.Info(ctx, fmt.Sprintf("Zone sync: %d sets to replace, %d to delete",
+		len(changes.Set), len(changes.Del)))
+
+	setKeys := make([]rrSetKey, 0, len(changes.Set))
+	for key := range changes.Set {
+		setKeys = append(setKeys, key)
+	}
+	sortRRSetKeys(setKeys)
+	for _, key := range setKeys {
+		tflog.Debug(ctx, fmt.Sprintf("Zone sync: replacing %s %s", key.Type, key.Name))
+		if err := r.client.SetRecords(ctx, apiDomain, key.Type, key.Name, changes.Set[key]); err != nil {
+			return fmt.Errorf("replacing %s records for %q: %w", key.Type, key.Name, err)
+		}
+	}
+	for _, key := range changes.Del {
+		tflog.Debug(ctx, fmt.Sprintf("Zone sync: deleting %s %s", key.Type, key.Name))
+		if err := r.client.DelRecords(ctx, apiDomain, key.Type, key.Name); err != nil {
+			return fmt.Errorf("deleting %s records for %q: %w", key.Type, key.Name, err)
+		}
+	}
+	return nil
+}
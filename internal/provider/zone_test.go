@@ -0,0 +1,179 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/veksh/terraform-provider-godaddy-dns/internal/model"
+)
+
+func TestDiffZone(t *testing.T) {
+	t.Parallel()
+	a1 := model.DNSRecord{Type: model.REC_A, Name: "@", Data: "1.1.1.1", TTL: 3600}
+	a2 := model.DNSRecord{Type: model.REC_A, Name: "@", Data: "2.2.2.2", TTL: 3600}
+	mx := model.DNSRecord{Type: model.REC_MX, Name: "@", Data: "mx.test.com", TTL: 3600, Priority: 10}
+	txt := model.DNSRecord{Type: model.REC_TXT, Name: "_test", Data: "text", TTL: 600}
+	txtTTL := txt
+	txtTTL.TTL = 3600
+	// as returned by API for configured values
+	cname := model.DNSRecord{Type: model.REC_CNAME, Name: "www", Data: "Target.Test.com", TTL: 3600}
+	cnameAPI := cname
+	cnameAPI.Data = "target.test.com"
+	mxAPI := mx
+	mxAPI.Data = "MX.test.com."
+	txtAPI := txt
+	txtAPI.Data = `"te" "xt"`
+
+	tests := []struct {
+		name    string
+		current []model.DNSRecord
+		desired []model.DNSRecord
+		wantSet map[rrSetKey][]model.DNSUpdateRecord
+		wantDel []rrSetKey
+	}{
+		{
+			name:    "same records in different order: noop",
+			current: []model.DNSRecord{a1, a2, mx},
+			desired: []model.DNSRecord{mx, a2, a1},
+			wantSet: map[rrSetKey][]model.DNSUpdateRecord{},
+		},
+		{
+			name:    "one value added: replace only that set",
+			current: []model.DNSRecord{a1, mx},
+			desired: []model.DNSRecord{a1, a2, mx},
+			wantSet: map[rrSetKey][]model.DNSUpdateRecord{
+				{model.REC_A, "@"}: {a1.ToUpdate(), a2.ToUpdate()},
+			},
+		},
+		{
+			name:    "ttl changed",
+			current: []model.DNSRecord{txt, mx},
+			desired: []model.DNSRecord{txtTTL, mx},
+			wantSet: map[rrSetKey][]model.DNSUpdateRecord{
+				{model.REC_TXT, "_test"}: {txtTTL.ToUpdate()},
+			},
+		},
+		{
+			name:    "set gone: delete",
+			current: []model.DNSRecord{a1, mx, txt},
+			desired: []model.DNSRecord{a1},
+			wantSet: map[rrSetKey][]model.DNSUpdateRecord{},
+			wantDel: []rrSetKey{{model.REC_MX, "@"}, {model.REC_TXT, "_test"}},
+		},
+		{
+			name:    "equivalent data: noop",
+			current: []model.DNSRecord{cnameAPI, mxAPI, txtAPI},
+			desired: []model.DNSRecord{cname, mx, txt},
+			wantSet: map[rrSetKey][]model.DNSUpdateRecord{},
+		},
+		{
+			name:    "equivalent data, ttl changed: replace with desired",
+			current: []model.DNSRecord{cnameAPI, txtAPI},
+			desired: []model.DNSRecord{cname, txtTTL},
+			wantSet: map[rrSetKey][]model.DNSUpdateRecord{
+				{model.REC_TXT, "_test"}: {txtTTL.ToUpdate()},
+			},
+		},
+		{
+			name:    "empty zone",
+			current: []model.DNSRecord{},
+			desired: []model.DNSRecord{mx},
+			wantSet: map[rrSetKey][]model.DNSUpdateRecord{
+				{model.REC_MX, "@"}: {mx.ToUpdate()},
+			},
+		},
+	}
+	for _, tt := range tests {
+		got := diffZone(tt.current, tt.desired)
+		if !cmp.Equal(tt.wantSet, got.Set) {
+			t.Errorf("%s: set mismatch: %s", tt.name, cmp.Diff(tt.wantSet, got.Set))
+		}
+		if !cmp.Equal(tt.wantDel, got.Del) {
+			t.Errorf("%s: del mismatch: %s", tt.name, cmp.Diff(tt.wantDel, got.Del))
+		}
+	}
+}
+
+func TestFilterIgnored(t *testing.T) {
+	t.Parallel()
+	recs := []model.DNSRecord{
+		{Type: model.REC_SOA, Name: "@", Data: "ns1.domaincontrol.com"},
+		{Type: model.REC_NS, Name: "@", Data: "ns1.domaincontrol.com"},
+		{Type: model.REC_NS, Name: "sub", Data: "ns1.other.com"},
+		{Type: model.REC_CNAME, Name: "_domainconnect", Data: "_domainconnect.gd.domaincontrol.com"},
+		{Type: model.REC_TXT, Name: "_acme-challenge.www", Data: "token"},
+		{Type: model.REC_A, Name: "@", Data: "1.1.1.1"},
+	}
+	filters := append(zoneDefaultIgnore, zoneIgnoreFilter{Name: "_acme-challenge*"})
+	got := filterIgnored(recs, filters)
+	want := []model.DNSRecord{recs[2], recs[5]}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+// create zone config with given A record values (plus MX)
+func zoneConfig(aValues ...string) string {
+	recs := `{ type = "MX", name = "@", data = "mx.test.com", priority = 10 },`
+	for _, v := range aValues {
+		recs += fmt.Sprintf(`{ type = "A", name = "www", data = %q },`, v)
+	}
+	return testProviderConfig + fmt.Sprintf(`
+	resource "godaddy-dns_zone" "test" {
+	  domain  = "%s"
+	  records = [%s]
+	}`, TEST_DOMAIN, recs)
+}
+
+// zone lifecycle: only changed sets are written, ignored records are never touched
+func TestUnitZoneLifecycle(t *testing.T) {
+	soa := model.DNSRecord{Type: model.REC_SOA, Name: "@", Data: "ns1.domaincontrol.com", TTL: 3600}
+	ns := model.DNSRecord{Type: model.REC_NS, Name: "@", Data: "ns1.domaincontrol.com", TTL: 3600}
+	mx := model.DNSRecord{Type: model.REC_MX, Name: "@", Data: "mx.test.com", TTL: 3600, Priority: 10}
+	stray := model.DNSRecord{Type: model.REC_TXT, Name: "stray", Data: "not in config", TTL: 600}
+	a1 := model.DNSRecord{Type: model.REC_A, Name: "www", Data: "1.1.1.1", TTL: 3600}
+	a2 := model.DNSRecord{Type: model.REC_A, Name: "www", Data: "2.2.2.2", TTL: 3600}
+	anyType := model.DNSRecordType("")
+
+	// create: mx is already there, stray must go, www is to be set
+	mClientAdd := model.NewMockDNSApiClient(t)
+	mClientAdd.EXPECT().GetRecords(mCtx, mDom, anyType, model.DNSRecordName("")).Return(
+		[]model.DNSRecord{soa, ns, mx, stray}, nil).Once()
+	mClientAdd.EXPECT().SetRecords(mCtx, mDom, model.REC_A, a1.Name,
+		[]model.DNSUpdateRecord{a1.ToUpdate()}).Return(nil).Once()
+	mClientAdd.EXPECT().DelRecords(mCtx, mDom, stray.Type, stray.Name).Return(nil).Once()
+	mClientAdd.EXPECT().GetRecords(mCtx, mDom, anyType, model.DNSRecordName("")).Return(
+		[]model.DNSRecord{soa, ns, mx, a1}, nil)
+
+	// update: add second A, then destroy everything but SOA and NS
+	mClientUpd := model.NewMockDNSApiClient(t)
+	mClientUpd.EXPECT().GetRecords(mCtx, mDom, anyType, model.DNSRecordName("")).Return(
+		[]model.DNSRecord{soa, ns, mx, a1}, nil).Twice()
+	mClientUpd.EXPECT().SetRecords(mCtx, mDom, model.REC_A, a1.Name,
+		updRecsInAnyOrder([]model.DNSUpdateRecord{a1.ToUpdate(), a2.ToUpdate()})).Return(nil).Once()
+	mClientUpd.EXPECT().GetRecords(mCtx, mDom, anyType, model.DNSRecordName("")).Return(
+		[]model.DNSRecord{soa, ns, mx, a1, a2}, nil)
+	mClientUpd.EXPECT().DelRecords(mCtx, mDom, model.REC_A, a1.Name).Return(nil).Once()
+	mClientUpd.EXPECT().DelRecords(mCtx, mDom, model.REC_MX, mx.Name).Return(nil).Once()
+
+	resource.UnitTest(t, resource.TestCase{
+		Steps: []resource.TestStep{
+			{
+				ProtoV6ProviderFactories: mockClientProviderFactory(mClientAdd),
+				Config:                   zoneConfig("1.1.1.1"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("godaddy-dns_zone.test", "records.#", "2"),
+				),
+			},
+			{
+				ProtoV6ProviderFactories: mockClientProviderFactory(mClientUpd),
+				Config:                   zoneConfig("1.1.1.1", "2.2.2.2"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("godaddy-dns_zone.test", "records.#", "3"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,161 @@
+package ratelimiter
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// rate limit signals from server reply
+type Feedback struct {
+	// request was throttled (429)
+	Throttled bool
+	// time to wait before next request (Retry-After), 0 if unknown
+	RetryAfter time.Duration
+	// requests left in current server window (X-RateLimit-Remaining), -1 if unknown
+	Remaining int
+	// time until server window reset (X-RateLimit-Reset), 0 if unknown
+	Reset time.Duration
+}
+
+// current limiter state, for logging
+type Stats struct {
+	// current rate, requests per second
+	Rate float64
+	// requests let through
+	Requests int64
+	// replies with throttling
+	Throttled int64
+}
+
+// limiters that adjust to server replies
+type FeedbackLimiter interface {
+	Limiter
+	Feedback(fb Feedback)
+	Stats() Stats
+}
+
+// adaptive rate limiter: requests are evenly spaced at current rate, which
+// is adjusted by server replies AIMD-style
+//   - starts at max rate (the configured one)
+//   - throttled reply: rate is halved (but not below 1/16 of max), requests are
+//     paused for Retry-After if it is set
+//   - successful reply: rate is increased by max/RPP, so it gets back to max
+//     after about one period without throttling
+//   - if server reports remaining quota, rate is capped to spread it until
+//     reset, and paused until reset if nothing is left
+//   - mutex is not held while waiting, so waits are cancellable and rate
+//     changes are picked up by waiting requests
+type AdaptiveRateLimiter struct {
+	mu    sync.Mutex
+	clock Clock
+	// rate limits and additive increase step, requests per second
+	maxRate  float64
+	minRate  float64
+	increase float64
+	// current rate
+	rate float64
+	// time of the last request: next one is spaced from it at current rate
+	last time.Time
+	// no requests until then (Retry-After, quota exhausted)
+	pausedUntil time.Time
+	requests    int64
+	throttled   int64
+}
+
+// max requests per period (and its length)
+func NewAdaptiveRL(period time.Duration, RPP int, opts ...Option) (FeedbackLimiter, error) {
+	if period <= 0 || RPP <= 0 {
+		return nil, errors.New("limiter period and num of requests must be positive")
+	}
+	maxRate := float64(RPP) / period.Seconds()
+	return &AdaptiveRateLimiter{
+		clock:    makeOptions(opts).clock,
+		maxRate:  maxRate,
+		minRate:  maxRate / 16,
+		increase: maxRate / float64(RPP),
+		rate:     maxRate,
+	}, nil
+}
+
+// block until request is allowed
+func (s *AdaptiveRateLimiter) Wait() {
+	_ = s.WaitCtx(context.Background())
+}
+
+// block until request is allowed, with cancellable context
+func (s *AdaptiveRateLimiter) WaitCtx(ctx context.Context) error {
+	for {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		waitDuration := s.tryAcquire()
+		if waitDuration <= 0 {
+			return nil
+		}
+		if err := sleepCtx(ctx, s.clock, waitDuration); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *AdaptiveRateLimiter) interval() time.Duration {
+	return time.Duration(float64(time.Second) / s.rate)
+}
+
+// register request if allowed, else return time to wait
+func (s *AdaptiveRateLimiter) tryAcquire() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.clock.Now()
+	start := s.last.Add(s.interval())
+	if s.pausedUntil.After(start) {
+		start = s.pausedUntil
+	}
+	if start.After(now) {
+		return start.Sub(now)
+	}
+	s.last = now
+	s.requests++
+	return 0
+}
+
+// adjust rate to server reply
+func (s *AdaptiveRateLimiter) Feedback(fb Feedback) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.clock.Now()
+	if fb.Throttled {
+		s.throttled++
+		s.rate = max(s.rate/2, s.minRate)
+		if fb.RetryAfter > 0 {
+			s.pause(now.Add(fb.RetryAfter))
+		}
+	} else {
+		s.rate = min(s.rate+s.increase, s.maxRate)
+	}
+	if fb.Remaining >= 0 && fb.Reset > 0 {
+		if fb.Remaining == 0 {
+			s.pause(now.Add(fb.Reset))
+		} else {
+			s.rate = max(min(s.rate, float64(fb.Remaining)/fb.Reset.Seconds()), s.minRate)
+		}
+	}
+}
+
+func (s *AdaptiveRateLimiter) pause(until time.Time) {
+	if until.After(s.pausedUntil) {
+		s.pausedUntil = until
+	}
+}
+
+func (s *AdaptiveRateLimiter) Stats() Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return Stats{
+		Rate:      s.rate,
+		Requests:  s.requests,
+		Throttled: s.throttled,
+	}
+}
@@ -0,0 +1,102 @@
+package ratelimiter
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+var noQuota = Feedback{Remaining: -1}
+
+func TestAdaptiveRL_EvenlySpaced(t *testing.T) {
+	t.Parallel()
+	clock := newTestClock()
+	l, err := NewAdaptiveRL(time.Minute, 60, WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertNextAfter(t, clock, l, 0)
+	assertNextAfter(t, clock, l, time.Second)
+	assertNextAfter(t, clock, l, time.Second)
+}
+
+func TestAdaptiveRL_SlowsDownWhenThrottled(t *testing.T) {
+	t.Parallel()
+	clock := newTestClock()
+	l, err := NewAdaptiveRL(time.Minute, 60, WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Wait()
+	l.Feedback(Feedback{Throttled: true, RetryAfter: 10 * time.Second, Remaining: -1})
+	// paused for Retry-After
+	assertNextAfter(t, clock, l, 10*time.Second)
+	// then at half rate
+	l.Feedback(Feedback{Throttled: true, Remaining: -1})
+	assertNextAfter(t, clock, l, 4*time.Second)
+	if st := l.Stats(); st.Rate != 0.25 || st.Requests != 3 || st.Throttled != 2 {
+		t.Errorf("unexpected stats %+v", st)
+	}
+	// not below the minimum
+	for i := 0; i < 10; i++ {
+		l.Feedback(Feedback{Throttled: true, Remaining: -1})
+	}
+	if rate := l.Stats().Rate; rate != 1.0/16 {
+		t.Errorf("want min rate 1/16, got %f", rate)
+	}
+}
+
+func TestAdaptiveRL_SpeedsUpGradually(t *testing.T) {
+	t.Parallel()
+	clock := newTestClock()
+	l, err := NewAdaptiveRL(time.Minute, 60, WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Feedback(Feedback{Throttled: true, Remaining: -1})
+	for i := 0; i < 30; i++ {
+		l.Feedback(noQuota)
+	}
+	if rate := l.Stats().Rate; rate < 0.99 || rate > 1.01 {
+		t.Errorf("want rate back at 1 after 30 successes, got %f", rate)
+	}
+	// not above the configured one
+	l.Feedback(noQuota)
+	if rate := l.Stats().Rate; rate != 1 {
+		t.Errorf("want max rate 1, got %f", rate)
+	}
+}
+
+func TestAdaptiveRL_FollowsServerQuota(t *testing.T) {
+	t.Parallel()
+	clock := newTestClock()
+	l, err := NewAdaptiveRL(time.Minute, 60, WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Wait()
+	// 5 requests left for 20 seconds
+	l.Feedback(Feedback{Remaining: 5, Reset: 20 * time.Second})
+	assertNextAfter(t, clock, l, 4*time.Second)
+	// nothing left: wait for reset
+	l.Feedback(Feedback{Remaining: 0, Reset: 30 * time.Second})
+	assertNextAfter(t, clock, l, 30*time.Second)
+}
+
+func TestAdaptiveRL_Cancel(t *testing.T) {
+	t.Parallel()
+	clock := newTestClock()
+	l, err := NewAdaptiveRL(time.Minute, 60, WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Wait()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := waitAsync(ctx, l)
+	blockUntil(t, clock, 1)
+	cancel()
+	assertDone(t, done, context.Canceled)
+	if st := l.Stats(); st.Requests != 1 {
+		t.Errorf("cancelled request counted: %+v", st)
+	}
+}
@@ -15,7 +15,8 @@ import (
 //   - spend one if now it is available
 //   - if none, wait until next one will be available (while holding mutex)
 type BucketRateLimiter struct {
-	mu sync.Mutex
+	mu    sync.Mutex
+	clock Clock
 	// interval between tokens, s
 	period time.Duration
 	// total bucket size; 1 means "no bursts"
@@ -27,43 +28,31 @@ type BucketRateLimiter struct {
 }
 
 // context to cancel, rate per second, burst (bucket) size
-func NewBucketRL(rate, burst int) (Limiter, error) {
+func NewBucketRL(rate, burst int, opts ...Option) (Limiter, error) {
 	if rate <= 0 || burst <= 0 {
 		return nil, errors.New("limiter rate and burst must be positive")
 	}
+	return NewBucketRLInterval(time.Second/time.Duration(rate), burst, opts...)
+}
+
+// interval between tokens (for rates below 1 RPS), burst (bucket) size
+func NewBucketRLInterval(interval time.Duration, burst int, opts ...Option) (Limiter, error) {
+	if interval <= 0 || burst <= 0 {
+		return nil, errors.New("limiter interval and burst must be positive")
+	}
+	o := makeOptions(opts)
 	return &BucketRateLimiter{
-		period:        time.Second / time.Duration(rate),
+		clock:         o.clock,
+		period:        interval,
 		bucketSize:    burst,
 		numTokens:     burst,
-		lastTokenTime: time.Now(),
+		lastTokenTime: o.clock.Now(),
 	}, nil
 }
 
 // block until token becomes available
 func (s *BucketRateLimiter) Wait() {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	// if bucket is empty: add tokens that are due, update last refill time
-	if s.numTokens <= 0 {
-		elapsedTime := time.Since(s.lastTokenTime)
-		tokensToAdd := int(elapsedTime.Nanoseconds() / s.period.Nanoseconds())
-		s.numTokens = tokensToAdd
-		if s.numTokens > s.bucketSize {
-			s.numTokens = s.bucketSize
-		}
-		s.lastTokenTime = s.lastTokenTime.Add(time.Duration(tokensToAdd) * s.period)
-	}
-	// if token available: ok, take it and release caller from the wait
-	if s.numTokens > 0 {
-		s.numTokens--
-		return
-	}
-	// if still no tokens: will have to wait until nextTokenTime or cancel
-	nextTokenTime := s.lastTokenTime.Add(s.period)
-	waitDuration := time.Until(nextTokenTime)
-	time.Sleep(waitDuration)
-	// upd last token time and release from the wait
-	s.lastTokenTime = nextTokenTime
+	_ = s.WaitCtx(context.Background())
 }
 
 // block until token becomes available, with cancellable context
@@ -75,7 +64,7 @@ func (s *BucketRateLimiter) WaitCtx(ctx context.Context) error {
 	defer s.mu.Unlock()
 	// if bucket is empty: add tokens that are due, update last refill time
 	if s.numTokens <= 0 {
-		elapsedTime := time.Since(s.lastTokenTime)
+		elapsedTime := s.clock.Now().Sub(s.lastTokenTime)
 		tokensToAdd := int(elapsedTime.Nanoseconds() / s.period.Nanoseconds())
 		s.numTokens = tokensToAdd
 		if s.numTokens > s.bucketSize {
@@ -90,11 +79,9 @@ func (s *BucketRateLimiter) WaitCtx(ctx context.Context) error {
 	}
 	// if still no tokens: will have to wait until nextTokenTime or cancel
 	nextTokenTime := s.lastTokenTime.Add(s.period)
-	waitDuration := time.Until(nextTokenTime)
-	select {
-	case <-ctx.Done():
-		return ctx.Err()
-	case <-time.After(waitDuration):
+	waitDuration := nextTokenTime.Sub(s.clock.Now())
+	if err := sleepCtx(ctx, s.clock, waitDuration); err != nil {
+		return err
 	}
 	// if not cancelled: upd last token time and release from the wait
 	s.lastTokenTime = nextTokenTime
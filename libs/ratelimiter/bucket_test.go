@@ -0,0 +1,80 @@
+package ratelimiter
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestBucketRL_BurstThenEvenlySpaced(t *testing.T) {
+	t.Parallel()
+	clock := newTestClock()
+	l, err := NewBucketRL(1, 3, WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		assertNextAfter(t, clock, l, 0)
+	}
+	assertNextAfter(t, clock, l, time.Second)
+	assertNextAfter(t, clock, l, time.Second)
+}
+
+func TestBucketRL_Refill(t *testing.T) {
+	t.Parallel()
+	clock := newTestClock()
+	l, err := NewBucketRLInterval(time.Second, 3, WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		l.Wait()
+	}
+	// 2 tokens added, half of the third one
+	clock.Advance(2500 * time.Millisecond)
+	assertNextAfter(t, clock, l, 0)
+	assertNextAfter(t, clock, l, 0)
+	assertNextAfter(t, clock, l, 500*time.Millisecond)
+	// no more than burst after long idle time
+	clock.Advance(time.Hour)
+	for i := 0; i < 3; i++ {
+		assertNextAfter(t, clock, l, 0)
+	}
+	assertNextAfter(t, clock, l, time.Second)
+}
+
+func TestBucketRL_Cancel(t *testing.T) {
+	t.Parallel()
+	clock := newTestClock()
+	l, err := NewBucketRLInterval(time.Second, 1, WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Wait()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := waitAsync(ctx, l)
+	blockUntil(t, clock, 1)
+	cancel()
+	assertDone(t, done, context.Canceled)
+	// already cancelled
+	if err := l.WaitCtx(ctx); err != context.Canceled {
+		t.Errorf("want cancelled, got %v", err)
+	}
+	// cancelled requests do not take tokens
+	clock.Advance(time.Second)
+	assertNextAfter(t, clock, l, 0)
+	assertNextAfter(t, clock, l, time.Second)
+}
+
+func TestNewBucketRL_BadParams(t *testing.T) {
+	t.Parallel()
+	if _, err := NewBucketRL(0, 1); err == nil {
+		t.Error("no error for zero rate")
+	}
+	if _, err := NewBucketRLInterval(time.Second, 0); err == nil {
+		t.Error("no error for zero burst")
+	}
+	if _, err := NewBucketRLInterval(0, 1); err == nil {
+		t.Error("no error for zero interval")
+	}
+}
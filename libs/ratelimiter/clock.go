@@ -0,0 +1,70 @@
+package ratelimiter
+
+import (
+	"context"
+	"time"
+)
+
+// time source for limiters: real one, or fake in tests
+type Clock interface {
+	Now() time.Time
+	NewTimer(d time.Duration) Timer
+}
+
+// like time.Timer: must be stopped if not waited for, to release it
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) NewTimer(d time.Duration) Timer {
+	return realTimer{time.NewTimer(d)}
+}
+
+type realTimer struct {
+	*time.Timer
+}
+
+func (t realTimer) C() <-chan time.Time {
+	return t.Timer.C
+}
+
+// wait for timer or context cancellation
+func sleepCtx(ctx context.Context, clock Clock, d time.Duration) error {
+	timer := clock.NewTimer(d)
+	select {
+	case <-ctx.Done():
+		timer.Stop()
+		return ctx.Err()
+	case <-timer.C():
+		return nil
+	}
+}
+
+// limiter options
+type Option func(*options)
+
+type options struct {
+	clock Clock
+}
+
+// use clock instead of the real time
+func WithClock(clock Clock) Option {
+	return func(o *options) {
+		o.clock = clock
+	}
+}
+
+func makeOptions(opts []Option) options {
+	res := options{clock: realClock{}}
+	for _, opt := range opts {
+		opt(&res)
+	}
+	return res
+}
@@ -0,0 +1,107 @@
+package ratelimiter
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// manually advanced clock for tests: After channels fire on Advance
+type FakeClock struct {
+	mu sync.Mutex
+	// signalled on new timers
+	cond   *sync.Cond
+	now    time.Time
+	timers []*fakeTimer
+}
+
+type fakeTimer struct {
+	clock *FakeClock
+	at    time.Time
+	ch    chan time.Time
+}
+
+func NewFakeClock(now time.Time) *FakeClock {
+	c := &FakeClock{now: now}
+	c.cond = sync.NewCond(&c.mu)
+	return c
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *FakeClock) NewTimer(d time.Duration) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &fakeTimer{clock: c, at: c.now.Add(d), ch: make(chan time.Time, 1)}
+	if d <= 0 {
+		t.ch <- c.now
+		return t
+	}
+	c.timers = append(c.timers, t)
+	c.cond.Broadcast()
+	return t
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.ch
+}
+
+// remove timer from pending, false if it is already fired
+func (t *fakeTimer) Stop() bool {
+	c := t.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	i := slices.Index(c.timers, t)
+	if i < 0 {
+		return false
+	}
+	c.timers = slices.Delete(c.timers, i, i+1)
+	return true
+}
+
+// move time forward, firing timers that are due
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	pending := c.timers[:0]
+	for _, t := range c.timers {
+		if t.at.After(c.now) {
+			pending = append(pending, t)
+		} else {
+			t.ch <- c.now
+		}
+	}
+	c.timers = pending
+}
+
+// number of timers that are not fired or stopped yet
+func (c *FakeClock) Waiters() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.timers)
+}
+
+// block until there are at least n pending timers: makes sure that waiting
+// goroutine got to the point of waiting before Advance; false if it did not
+// happen within timeout (of real time), e.g. because of a limiter bug
+func (c *FakeClock) BlockUntil(n int, timeout time.Duration) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	timedOut := false
+	timer := time.AfterFunc(timeout, func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		timedOut = true
+		c.cond.Broadcast()
+	})
+	defer timer.Stop()
+	for len(c.timers) < n && !timedOut {
+		c.cond.Wait()
+	}
+	return len(c.timers) >= n
+}
@@ -0,0 +1,142 @@
+package ratelimiter
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+)
+
+// cross-process rate limiter: sliding log of request times is kept in
+// a shared file, so all the processes using the same file share one budget
+//   - on acquire: lock file, drop times older than period; if there are
+//     less than RPP left, append current time and return
+//   - if not, unlock and wait until the oldest one expires, then retry
+//   - file is locked only for read + write, so waits are cancellable
+//   - file format: one request time (unix nanoseconds) per line
+type FileRateLimiter struct {
+	clock Clock
+	path  string
+	// sliding window length
+	period time.Duration
+	// max requests per window
+	bucketSize int
+}
+
+// state file path, period length and num of requests per period
+func NewFileRL(path string, period time.Duration, RPP int, opts ...Option) (Limiter, error) {
+	if period <= 0 || RPP <= 0 {
+		return nil, errors.New("limiter period and num of requests must be positive")
+	}
+	if path == "" {
+		return nil, errors.New("limiter state file path must be set")
+	}
+	// check early that file is usable
+	f, err := openLocked(path)
+	if err != nil {
+		return nil, err
+	}
+	if err = unlockClose(f); err != nil {
+		return nil, err
+	}
+	return &FileRateLimiter{
+		clock:      makeOptions(opts).clock,
+		path:       path,
+		period:     period,
+		bucketSize: RPP,
+	}, nil
+}
+
+// block until request is allowed
+func (s *FileRateLimiter) Wait() {
+	_ = s.WaitCtx(context.Background())
+}
+
+// block until request is allowed, with cancellable context; state file
+// errors are returned too
+func (s *FileRateLimiter) WaitCtx(ctx context.Context) error {
+	for {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		waitDuration, err := s.tryAcquire()
+		if err != nil {
+			return err
+		}
+		if waitDuration <= 0 {
+			return nil
+		}
+		if err := sleepCtx(ctx, s.clock, waitDuration); err != nil {
+			return err
+		}
+	}
+}
+
+// register request if allowed, else return time to wait
+func (s *FileRateLimiter) tryAcquire() (time.Duration, error) {
+	f, err := openLocked(s.path)
+	if err != nil {
+		return 0, err
+	}
+	defer unlockClose(f) //nolint:errcheck
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return 0, fmt.Errorf("cannot read limiter state: %w", err)
+	}
+	now := s.clock.Now()
+	// garbage (e.g. from crashed writer) is skipped
+	times := []time.Time{}
+	for _, line := range bytes.Fields(data) {
+		nanos, err := strconv.ParseInt(string(line), 10, 64)
+		if err != nil {
+			continue
+		}
+		if t := time.Unix(0, nanos); now.Sub(t) < s.period && !t.After(now) {
+			times = append(times, t)
+		}
+	}
+	// ok to return without writing: expired times are dropped next time
+	if len(times) >= s.bucketSize {
+		oldest := times[len(times)-s.bucketSize]
+		return oldest.Add(s.period).Sub(now), nil
+	}
+
+	var buf bytes.Buffer
+	for _, t := range append(times, now) {
+		buf.WriteString(strconv.FormatInt(t.UnixNano(), 10))
+		buf.WriteByte('\n')
+	}
+	if err = f.Truncate(0); err == nil {
+		_, err = f.WriteAt(buf.Bytes(), 0)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("cannot write limiter state: %w", err)
+	}
+	return 0, nil
+}
+
+// open state file (create if missing) and lock it exclusively
+func openLocked(path string) (*os.File, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open limiter state: %w", err)
+	}
+	if err = lockFile(f); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("cannot lock limiter state: %w", err)
+	}
+	return f, nil
+}
+
+func unlockClose(f *os.File) error {
+	err := unlockFile(f)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
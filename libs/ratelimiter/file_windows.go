@@ -0,0 +1,22 @@
+//go:build windows
+
+package ratelimiter
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// lock the whole file: flock equivalent
+const lockRange = ^uint32(0)
+
+// blocking exclusive lock, released on close too
+func lockFile(f *os.File) error {
+	return windows.LockFileEx(windows.Handle(f.Fd()), windows.LOCKFILE_EXCLUSIVE_LOCK,
+		0, lockRange, lockRange, &windows.Overlapped{})
+}
+
+func unlockFile(f *os.File) error {
+	return windows.UnlockFileEx(windows.Handle(f.Fd()), 0, lockRange, lockRange, &windows.Overlapped{})
+}
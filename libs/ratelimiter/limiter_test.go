@@ -0,0 +1,97 @@
+package ratelimiter
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func newTestClock() *FakeClock {
+	return NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+}
+
+// wait for n pending timers, failing the test instead of hanging
+func blockUntil(t *testing.T, clock *FakeClock, n int) {
+	t.Helper()
+	if !clock.BlockUntil(n, 5*time.Second) {
+		t.Fatalf("want %d pending timers, got %d", n, clock.Waiters())
+	}
+}
+
+// run WaitCtx in background, result is sent to channel
+func waitAsync(ctx context.Context, l Limiter) chan error {
+	done := make(chan error, 1)
+	go func() {
+		done <- l.WaitCtx(ctx)
+	}()
+	return done
+}
+
+func assertBlocked(t *testing.T, done chan error) {
+	t.Helper()
+	select {
+	case err := <-done:
+		t.Fatalf("request let through too early (err: %v)", err)
+	default:
+	}
+}
+
+func assertDone(t *testing.T, done chan error, want error) {
+	t.Helper()
+	select {
+	case err := <-done:
+		if !errors.Is(err, want) {
+			t.Fatalf("want %v, got %v", want, err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("request is still blocked")
+	}
+}
+
+// check that next request is let through exactly after wait
+func assertNextAfter(t *testing.T, clock *FakeClock, l Limiter, wait time.Duration) {
+	t.Helper()
+	done := waitAsync(context.Background(), l)
+	if wait > 0 {
+		blockUntil(t, clock, 1)
+		clock.Advance(wait - time.Millisecond)
+		assertBlocked(t, done)
+		clock.Advance(time.Millisecond)
+	}
+	assertDone(t, done, nil)
+}
+
+func TestFakeClock_BlockUntilTimeout(t *testing.T) {
+	t.Parallel()
+	clock := newTestClock()
+	if clock.BlockUntil(1, 10*time.Millisecond) {
+		t.Error("want timeout without timers")
+	}
+	timer := clock.NewTimer(time.Second)
+	if !clock.BlockUntil(1, 10*time.Millisecond) {
+		t.Error("want timer to be pending")
+	}
+	if !timer.Stop() || timer.Stop() {
+		t.Error("want only the first stop to succeed")
+	}
+}
+
+// waiter cancelled by context must not be left in pending timers
+func TestFakeClock_CancelledWaiterRemoved(t *testing.T) {
+	t.Parallel()
+	clock := newTestClock()
+	l, err := NewWindowRL(time.Second, 1, WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertDone(t, waitAsync(context.Background(), l), nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := waitAsync(ctx, l)
+	blockUntil(t, clock, 1)
+	cancel()
+	assertDone(t, done, context.Canceled)
+	if n := clock.Waiters(); n != 0 {
+		t.Errorf("want no pending timers after cancel, got %d", n)
+	}
+}
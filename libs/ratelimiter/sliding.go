@@ -0,0 +1,79 @@
+package ratelimiter
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// sliding log rate limiter: no more than `bucketSize` requests in any
+// `period`-long interval (GoDaddy counts requests over rolling window)
+//   - keeps times of the last `bucketSize` requests
+//   - on acquire: if there are less than `bucketSize` of them within period,
+//     record current time and return immediately
+//   - if not, wait until the oldest one leaves the window and retry
+//   - unlike fixed window, there are no double bursts at window boundary;
+//     after initial burst requests are let through as old ones expire
+//   - mutex is not held while waiting, so waits are cancellable
+type SlidingWindowRateLimiter struct {
+	mu    sync.Mutex
+	clock Clock
+	// sliding window length
+	period time.Duration
+	// max requests per window
+	bucketSize int
+	// times of requests within window, oldest first
+	log []time.Time
+}
+
+// period length and num of requests per period
+func NewSlidingRL(period time.Duration, RPP int, opts ...Option) (Limiter, error) {
+	if period <= 0 || RPP <= 0 {
+		return nil, errors.New("limiter period and num of requests must be positive")
+	}
+	return &SlidingWindowRateLimiter{
+		clock:      makeOptions(opts).clock,
+		period:     period,
+		bucketSize: RPP,
+		log:        make([]time.Time, 0, RPP),
+	}, nil
+}
+
+// block until request is allowed
+func (s *SlidingWindowRateLimiter) Wait() {
+	_ = s.WaitCtx(context.Background())
+}
+
+// block until request is allowed, with cancellable context
+func (s *SlidingWindowRateLimiter) WaitCtx(ctx context.Context) error {
+	for {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		waitDuration := s.tryAcquire()
+		if waitDuration <= 0 {
+			return nil
+		}
+		if err := sleepCtx(ctx, s.clock, waitDuration); err != nil {
+			return err
+		}
+	}
+}
+
+// register request if allowed, else return time to wait
+func (s *SlidingWindowRateLimiter) tryAcquire() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.clock.Now()
+	expired := 0
+	for expired < len(s.log) && now.Sub(s.log[expired]) >= s.period {
+		expired++
+	}
+	s.log = append(s.log[:0], s.log[expired:]...)
+	if len(s.log) >= s.bucketSize {
+		return s.log[0].Add(s.period).Sub(now)
+	}
+	s.log = append(s.log, now)
+	return 0
+}
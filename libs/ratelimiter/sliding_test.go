@@ -0,0 +1,80 @@
+package ratelimiter
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSlidingRL_AllowsUpToLimit(t *testing.T) {
+	t.Parallel()
+	clock := newTestClock()
+	l, err := NewSlidingRL(time.Minute, 3, WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := l.WaitCtx(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	done := waitAsync(context.Background(), l)
+	blockUntil(t, clock, 1)
+	clock.Advance(59 * time.Second)
+	assertBlocked(t, done)
+	clock.Advance(time.Second)
+	assertDone(t, done, nil)
+}
+
+func TestSlidingRL_NoBurstAtBoundary(t *testing.T) {
+	t.Parallel()
+	clock := newTestClock()
+	l, err := NewSlidingRL(time.Minute, 3, WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 1 at 0s, 2 at 50s
+	l.Wait()
+	clock.Advance(50 * time.Second)
+	l.Wait()
+	l.Wait()
+	// at 61s first one is out of window: fixed window would allow 3 more
+	clock.Advance(11 * time.Second)
+	assertDone(t, waitAsync(context.Background(), l), nil)
+	done := waitAsync(context.Background(), l)
+	blockUntil(t, clock, 1)
+	assertBlocked(t, done)
+	// next slot is at 110s
+	clock.Advance(48 * time.Second)
+	assertBlocked(t, done)
+	clock.Advance(time.Second)
+	assertDone(t, done, nil)
+}
+
+func TestSlidingRL_Cancel(t *testing.T) {
+	t.Parallel()
+	clock := newTestClock()
+	l, err := NewSlidingRL(time.Minute, 1, WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Wait()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := waitAsync(ctx, l)
+	blockUntil(t, clock, 1)
+	cancel()
+	assertDone(t, done, context.Canceled)
+	// cancelled request does not take a slot
+	clock.Advance(time.Minute)
+	assertDone(t, waitAsync(context.Background(), l), nil)
+}
+
+func TestNewSlidingRL_BadParams(t *testing.T) {
+	t.Parallel()
+	if _, err := NewSlidingRL(0, 10); err == nil {
+		t.Error("no error for zero period")
+	}
+	if _, err := NewSlidingRL(time.Minute, 0); err == nil {
+		t.Error("no error for zero requests")
+	}
+}
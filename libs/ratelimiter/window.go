@@ -17,7 +17,8 @@ import (
 //     followed by the large wait for next: alternative is 1 RPS which is way slower
 //     for shorter batches
 type WindowRateLimiter struct {
-	mu sync.Mutex
+	mu    sync.Mutex
+	clock Clock
 	// interval between bucket refills
 	period time.Duration
 	// total bucket size: num of requests per period
@@ -29,37 +30,23 @@ type WindowRateLimiter struct {
 }
 
 // period length and num of requests per period
-func NewWindowRL(period time.Duration, RPP int) (Limiter, error) {
+func NewWindowRL(period time.Duration, RPP int, opts ...Option) (Limiter, error) {
 	if period <= 0 || RPP <= 0 {
 		return nil, errors.New("limiter period and num of requests must be positive")
 	}
+	o := makeOptions(opts)
 	return &WindowRateLimiter{
+		clock:          o.clock,
 		period:         period,
 		bucketSize:     RPP,
 		numTokens:      RPP,
-		lastRefillTime: time.Now(),
+		lastRefillTime: o.clock.Now(),
 	}, nil
 }
 
 // block until token becomes available
 func (s *WindowRateLimiter) Wait() {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	// if last refilled happened long ago: refill now
-	if time.Since(s.lastRefillTime) > s.period {
-		s.lastRefillTime = time.Now()
-		s.numTokens = s.bucketSize
-	}
-	// if bucket is empty: wait for next refill
-	if s.numTokens == 0 {
-		nextTokenTime := s.lastRefillTime.Add(s.period)
-		waitDuration := time.Until(nextTokenTime)
-		time.Sleep(waitDuration)
-
-		s.lastRefillTime = time.Now()
-		s.numTokens = s.bucketSize
-	}
-	s.numTokens--
+	_ = s.WaitCtx(context.Background())
 }
 
 // block until token becomes available, with cancellable context
@@ -71,22 +58,20 @@ func (s *WindowRateLimiter) WaitCtx(ctx context.Context) error {
 	defer s.mu.Unlock()
 
 	// if last refilled happened long ago: refill now
-	if time.Since(s.lastRefillTime) > s.period {
-		s.lastRefillTime = time.Now()
+	if s.clock.Now().Sub(s.lastRefillTime) > s.period {
+		s.lastRefillTime = s.clock.Now()
 		s.numTokens = s.bucketSize
 	}
 	// if bucket is empty: wait for next refill
 	if s.numTokens == 0 {
 		nextTokenTime := s.lastRefillTime.Add(s.period)
-		waitDuration := time.Until(nextTokenTime)
+		waitDuration := nextTokenTime.Sub(s.clock.Now())
 
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case <-time.After(waitDuration):
+		if err := sleepCtx(ctx, s.clock, waitDuration); err != nil {
+			return err
 		}
 
-		s.lastRefillTime = time.Now()
+		s.lastRefillTime = s.clock.Now()
 		s.numTokens = s.bucketSize
 	}
 	s.numTokens--
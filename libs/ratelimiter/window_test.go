@@ -0,0 +1,90 @@
+package ratelimiter
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestWindowRL_BurstThenWait(t *testing.T) {
+	t.Parallel()
+	clock := newTestClock()
+	l, err := NewWindowRL(time.Minute, 3, WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		assertNextAfter(t, clock, l, 0)
+	}
+	// rest of the window
+	assertNextAfter(t, clock, l, time.Minute)
+}
+
+func TestWindowRL_Rollover(t *testing.T) {
+	t.Parallel()
+	clock := newTestClock()
+	l, err := NewWindowRL(time.Minute, 3, WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// idle for more than period: new window starts with the next request
+	l.Wait()
+	l.Wait()
+	clock.Advance(61 * time.Second)
+	for i := 0; i < 3; i++ {
+		assertNextAfter(t, clock, l, 0)
+	}
+	assertNextAfter(t, clock, l, time.Minute)
+}
+
+func TestWindowRL_BurstAtBoundary(t *testing.T) {
+	t.Parallel()
+	clock := newTestClock()
+	l, err := NewWindowRL(time.Minute, 3, WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// leftovers of the old window and then the whole new one are let
+	// through at boundary
+	l.Wait()
+	clock.Advance(time.Minute)
+	for i := 0; i < 5; i++ {
+		assertNextAfter(t, clock, l, 0)
+	}
+	assertNextAfter(t, clock, l, time.Minute)
+}
+
+func TestWindowRL_Cancel(t *testing.T) {
+	t.Parallel()
+	clock := newTestClock()
+	l, err := NewWindowRL(time.Minute, 2, WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Wait()
+	l.Wait()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := waitAsync(ctx, l)
+	blockUntil(t, clock, 1)
+	cancel()
+	assertDone(t, done, context.Canceled)
+	// already cancelled
+	if err := l.WaitCtx(ctx); err != context.Canceled {
+		t.Errorf("want cancelled, got %v", err)
+	}
+	// cancelled requests do not take tokens from the next window
+	clock.Advance(time.Minute)
+	assertNextAfter(t, clock, l, 0)
+	assertNextAfter(t, clock, l, 0)
+	assertNextAfter(t, clock, l, time.Minute)
+}
+
+func TestNewWindowRL_BadParams(t *testing.T) {
+	t.Parallel()
+	if _, err := NewWindowRL(0, 10); err == nil {
+		t.Error("no error for zero period")
+	}
+	if _, err := NewWindowRL(time.Minute, 0); err == nil {
+		t.Error("no error for zero requests")
+	}
+}
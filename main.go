@@ -5,7 +5,9 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
 	"log"
+	"os"
 
 	"github.com/hashicorp/terraform-plugin-framework/providerserver"
 	"github.com/veksh/terraform-provider-godaddy-dns/internal/client"
@@ -21,6 +23,13 @@ var (
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "export" {
+		if err := export(os.Args[2:]); err != nil {
+			log.Fatal(err.Error())
+		}
+		return
+	}
+
 	var debug bool
 
 	flag.BoolVar(&debug, "debug", false, "set to true to run the provider with support for debuggers like delve")
@@ -31,8 +40,8 @@ func main() {
 		Debug:   debug,
 	}
 
-	apiClientFactory := func(apiURL, apiKey, apiSecret string) (model.DNSApiClient, error) {
-		return client.NewClient(apiURL, apiKey, apiSecret)
+	apiClientFactory := func(apiURL, apiKey, apiSecret string, opts ...client.Option) (model.DNSApiClient, error) {
+		return client.NewClient(apiURL, apiKey, apiSecret, opts...)
 	}
 
 	err := providerserver.Serve(context.Background(), provider.New(version, apiClientFactory), opts)
@@ -41,3 +50,54 @@ func main() {
 		log.Fatal(err.Error())
 	}
 }
+
+// export subcommand: dump domain records as HCL resources + import blocks;
+// credentials are taken from the same env vars as for the provider
+func export(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	domain := flags.String("domain", "", "domain to export, like mydom.com (required)")
+	apiURL := flags.String("api-url", os.Getenv("GODADDY_API_URL"),
+		"GoDaddy API URL, overrides environment (could be set with GODADDY_API_URL)")
+	environment := flags.String("environment", os.Getenv("GODADDY_ENVIRONMENT"),
+		"GoDaddy API environment: production (default) or ote (could be set with GODADDY_ENVIRONMENT)")
+	shopperID := flags.String("shopper-id", os.Getenv("GODADDY_SHOPPER_ID"),
+		"shopper ID for reseller sub-account (could be set with GODADDY_SHOPPER_ID)")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s export --domain <domain> > records.tf\n\n"+
+			"Writes HCL godaddy-dns_record resources with import blocks for all the records of domain.\n"+
+			"GODADDY_API_KEY and GODADDY_API_SECRET environment variables must be set.\n\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if *domain == "" {
+		flags.Usage()
+		return fmt.Errorf("domain is required")
+	}
+	apiKey, apiSecret := os.Getenv("GODADDY_API_KEY"), os.Getenv("GODADDY_API_SECRET")
+	if apiKey == "" || apiSecret == "" {
+		return fmt.Errorf("GODADDY_API_KEY and GODADDY_API_SECRET environment variables must be set")
+	}
+	// explicit environment flag overrides url from env, like in provider config
+	if isFlagSet(flags, "environment") && !isFlagSet(flags, "api-url") {
+		*apiURL = ""
+	}
+	url, err := provider.APIURL(*apiURL, *environment)
+	if err != nil {
+		return err
+	}
+	apiClient, err := client.NewClient(url, apiKey, apiSecret, client.WithShopperID(*shopperID))
+	if err != nil {
+		return err
+	}
+	return provider.ExportDomain(context.Background(), apiClient, model.DNSDomain(*domain), os.Stdout)
+}
+
+// true if flag was set on command line
+func isFlagSet(flags *flag.FlagSet, name string) bool {
+	set := false
+	flags.Visit(func(f *flag.Flag) {
+		set = set || f.Name == name
+	})
+	return set
+}